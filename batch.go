@@ -0,0 +1,400 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Target 表示一个待查询的服务器地址
+type Target struct {
+	Name    string // 显示名称 (可选, 如 servers.dat 中保存的服务器名称)
+	Address string // 用户输入的地址, 如 mc.example.com:25565
+}
+
+// 从文本读取服务器地址列表, 每行一个地址, 忽略空行与 # 开头的注释
+func readTargets(r io.Reader) ([]Target, error) {
+	var targets []Target
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		targets = append(targets, Target{Address: line})
+	}
+	return targets, scanner.Err()
+}
+
+// 从文件读取服务器地址列表, path 为 - 时读取标准输入
+func readTargetFile(path string) ([]Target, error) {
+	if path == "-" {
+		return readTargets(os.Stdin)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return readTargets(f)
+}
+
+// 查询选项
+type queryOptions struct {
+	Port    int             // --port 指定的端口, 0 表示未指定
+	Timeout time.Duration   // 连接超时, 0 表示直到 TCP 超时
+	Retries int             // 查询失败后的最大重试次数
+	RetryOn map[string]bool // 允许重试的错误分类代码
+
+	RetryEmpty []string // 状态中必需的字段, 响应为空或缺少这些字段时也会重试 (--retry-on-empty), nil 表示不启用
+
+	ProbeLogin string // 查询状态后以该用户名尝试登录 (--probe-login), 仅单个服务器时有效
+
+	HandshakeOriginal bool   // 握手包中声明用户输入的主机名, 而非 SRV 记录指向的主机名 (--handshake-original)
+	HandshakeHost     string // 本次查询握手包中声明的主机名, 为空时使用实际连接的主机名 (由 forAddress 设置)
+}
+
+// 针对指定地址的查询选项
+func (o queryOptions) forAddress(addr string) queryOptions {
+	if o.HandshakeOriginal {
+		o.HandshakeHost, _, _ = splitAddress(addr)
+	}
+	return o
+}
+
+// 批量查询选项
+type batchOptions struct {
+	Concurrency int    // 最大并发数
+	JSON        bool   // 全部完成后输出 JSON 数组
+	NDJSON      bool   // 每完成一个即输出一行 JSON
+	Sort        string // 排序方式: ping / players / name, 为空时按完成顺序输出
+	FailFast    bool   // 出现第一个失败后不再开始新的查询
+	Table       bool   // 全部完成后以对齐的表格输出
+	OnlyUp      bool   // 仅输出查询成功的服务器
+	OnlyDown    bool   // 仅输出查询失败的服务器
+
+	TotalTimeout time.Duration // 整个批量查询的时间上限 (--total-timeout), 0 表示不限制; 每个服务器仍使用各自的连接超时
+}
+
+// 结果是否需要输出, 汇总统计不受影响
+func (b batchOptions) shows(r queryResult) bool {
+	switch {
+	case b.OnlyUp:
+		return r.Err == nil
+	case b.OnlyDown:
+		return r.Err != nil
+	}
+	return true
+}
+
+// 单个服务器的查询结果
+type queryResult struct {
+	Target Target
+	Host   string        // 实际连接的主机名 (可能来自 SRV 记录)
+	Port   uint16        // 实际连接的端口
+	IP     string        // 解析得到的 IP 地址 (仅用于展示)
+	Time   time.Time     // 查询时间
+	Status *ServerStatus // 查询成功时的服务器状态
+	Err    error         // 查询失败的原因
+}
+
+// 解析目标地址 (包括 SRV 记录与 IP 地址)
+func resolveTarget(t Target, opts queryOptions) queryResult {
+	r := queryResult{Target: t}
+	r.Host, r.Port, r.Err = parseAddress(t.Address, opts.Port)
+	if r.Err == nil {
+		r.IP = resolveHostToIP(r.Host)
+	}
+	return r
+}
+
+// 查询已解析的目标
+func (r *queryResult) query(opts queryOptions) {
+	r.Time = time.Now()
+	if r.Err != nil {
+		return
+	}
+	r.Status, r.Err = queryWithRetry(r.Host, r.Port, opts.forAddress(r.Target.Address))
+}
+
+// 以 JSON 对象形式输出查询结果 (状态字段与错误信息合并在同一对象中)
+func (r queryResult) MarshalJSON() ([]byte, error) {
+	out := struct {
+		Name      string `json:"name,omitempty"`
+		Address   string `json:"address"`
+		Host      string `json:"host,omitempty"`
+		Port      uint16 `json:"port,omitempty"`
+		IP        string `json:"ip,omitempty"`
+		Time      string `json:"time,omitempty"`
+		Online    bool   `json:"online"`
+		RTTMs     *int64 `json:"client_rtt_ms,omitempty"` // 本机测得的往返延迟
+		RTTFrom   string `json:"client_rtt_source,omitempty"`
+		PingError string `json:"ping_error,omitempty"`     // 收到状态后 ping 失败的原因
+		TCPMs     *int64 `json:"tcp_connect_ms,omitempty"` // TCP 连接的建立耗时 (--tcp-ping)
+		*ServerStatus
+		DefaultIcon *bool `json:"default_icon,omitempty"` // 图标与已知的默认图标相同
+		NoIcon      *bool `json:"no_icon,omitempty"`      // 服务器未返回图标
+		Proxy       *bool `json:"proxy,omitempty"`        // 根据版本名称推测是否为代理端
+
+		ExtraFields map[string]json.RawMessage `json:"extra_fields,omitempty"` // 非标准字段 (--extra-fields)
+		Error       string                     `json:"error,omitempty"`
+		Code        string                     `json:"code,omitempty"` // 错误分类代码, 如 CONNECTION_REFUSED
+	}{
+		Name:         r.Target.Name,
+		Address:      r.Target.Address,
+		Host:         r.Host,
+		Port:         r.Port,
+		IP:           r.IP,
+		Online:       r.Err == nil,
+		ServerStatus: r.Status,
+	}
+	if !r.Time.IsZero() {
+		out.Time = r.Time.Format(time.RFC3339)
+	}
+	if r.Status != nil {
+		ping := r.Status.Ping.Milliseconds()
+		out.RTTMs = &ping
+		out.RTTFrom = "ping"
+		if skipPing || r.Status.PingErr != nil {
+			out.RTTFrom = "status"
+		}
+		if showTCPPing {
+			tcp := r.Status.ConnectRTT.Milliseconds()
+			out.TCPMs = &tcp
+		}
+		if r.Status.PingErr != nil {
+			out.PingError = r.Status.PingErr.Error()
+		}
+		kind, _ := classifyFavicon(r.Status.Favicon)
+		defaultIcon, noIcon := kind == faviconDefault, kind == faviconNone
+		out.DefaultIcon, out.NoIcon = &defaultIcon, &noIcon
+		_, proxy := detectProxy(r.Status.versionName())
+		out.Proxy = &proxy
+		if showExtraFields {
+			out.ExtraFields = extraStatusFields(r.Status.Raw)
+		}
+	}
+	if r.Err != nil {
+		out.Error = r.Err.Error()
+		out.Code = r.errorCode()
+	}
+	return json.Marshal(out)
+}
+
+// 查询失败原因的分类代码
+func (r queryResult) errorCode() string {
+	switch {
+	case r.Status != nil:
+		return errCodeResponse
+	case r.Port == 0:
+		return errCodeAddress
+	}
+	return classifyError(r.Err)
+}
+
+// 并发查询多个目标, 每完成一个即调用 fn (fn 的调用是串行的)
+// fn 返回 false 时不再开始新的查询, 已在进行中的查询结果仍会传给 fn
+func runBatch(targets []Target, opts queryOptions, concurrency int, fn func(int, queryResult) bool) {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	type indexed struct {
+		i int
+		r queryResult
+	}
+	results := make(chan indexed)
+	sem := make(chan struct{}, concurrency)
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	for i, t := range targets {
+		wg.Add(1)
+		go func(i int, t Target) {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+			case <-stop:
+				return
+			}
+			select {
+			case <-stop:
+				<-sem
+				return
+			default:
+			}
+			r := resolveTarget(t, opts)
+			r.query(opts)
+			results <- indexed{i, r}
+		}(i, t)
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	// 处理完结果后才释放并发名额, 保证停止后不会再有新的查询开始
+	stopped := false
+	for res := range results {
+		if !fn(res.i, res.r) && !stopped {
+			stopped = true
+			close(stop)
+		}
+		<-sem
+	}
+}
+
+// 批量查询多个服务器并输出结果, 返回查询失败的服务器数量
+func runBatchMode(w io.Writer, targets []Target, opts queryOptions, display displayOptions, batch batchOptions) int {
+	if batch.TotalTimeout > 0 {
+		// 超过时间上限后, 进行中与尚未开始的查询均会因超时失败
+		prev := queryDeadline
+		if deadline := time.Now().Add(batch.TotalTimeout); prev.IsZero() || deadline.Before(prev) {
+			queryDeadline = deadline
+		}
+		defer func() { queryDeadline = prev }()
+	}
+
+	emit := func(r queryResult) { printResult(w, r, display) }
+	if batch.NDJSON {
+		emit = func(r queryResult) {
+			line, _ := json.Marshal(r)
+			fmt.Fprintln(w, string(line))
+		}
+	} else if !batch.JSON && !display.Quiet {
+		fmt.Printf("正在尝试获取 %d 个服务器的 MOTD 信息...\n", len(targets))
+	}
+
+	var summary batchSummary
+	collect := func(r queryResult) bool {
+		summary.add(r)
+		return r.Err == nil || !batch.FailFast
+	}
+	defer func() {
+		if !batch.JSON && !batch.NDJSON {
+			fmt.Fprintln(w, "\n"+summary.String())
+		}
+	}()
+
+	// 需要排序、输出数组或表格时, 先缓存全部结果
+	if batch.JSON || batch.Table || batch.Sort != "" {
+		type indexed struct {
+			i int
+			r queryResult
+		}
+		var buffered []indexed
+		runBatch(targets, opts, batch.Concurrency, func(i int, r queryResult) bool {
+			buffered = append(buffered, indexed{i, r})
+			return collect(r)
+		})
+		sort.Slice(buffered, func(a, b int) bool { return buffered[a].i < buffered[b].i })
+		results := make([]queryResult, 0, len(buffered))
+		for _, b := range buffered {
+			if batch.shows(b.r) {
+				results = append(results, b.r)
+			}
+		}
+		sortResults(results, batch.Sort)
+		if batch.JSON {
+			out, _ := marshalJSONOutput(results)
+			fmt.Fprintln(w, string(out))
+			return summary.failed()
+		}
+		if batch.Table {
+			fmt.Fprintln(w)
+			printTable(w, results)
+			return summary.failed()
+		}
+		for _, r := range results {
+			emit(r)
+		}
+		return summary.failed()
+	}
+
+	runBatch(targets, opts, batch.Concurrency, func(_ int, r queryResult) bool {
+		if batch.shows(r) {
+			emit(r)
+		}
+		return collect(r)
+	})
+	return summary.failed()
+}
+
+// 批量查询的汇总统计
+type batchSummary struct {
+	total, up int
+	pingSum   time.Duration
+	players   int
+}
+
+func (s *batchSummary) add(r queryResult) {
+	s.total++
+	if r.Err != nil {
+		return
+	}
+	s.up++
+	s.pingSum += r.Status.Ping
+	if r.Status.Players != nil {
+		s.players += r.Status.Players.Online
+	}
+}
+
+func (s *batchSummary) failed() int {
+	return s.total - s.up
+}
+
+func (s *batchSummary) String() string {
+	if s.up == 0 {
+		return fmt.Sprintf("汇总: %d/%d 在线", s.up, s.total)
+	}
+	avg := s.pingSum / time.Duration(s.up)
+	return fmt.Sprintf("汇总: %d/%d 在线, 平均延迟 %dms, 总在线人数 %d", s.up, s.total, avg.Milliseconds(), s.players)
+}
+
+// 按指定字段排序结果, 查询失败的服务器始终排在最后
+func sortResults(results []queryResult, by string) {
+	if by == "" {
+		return
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		a, b := results[i], results[j]
+		if (a.Err == nil) != (b.Err == nil) {
+			return a.Err == nil
+		}
+		if a.Err != nil {
+			return false
+		}
+		switch by {
+		case "ping":
+			return a.Status.Ping < b.Status.Ping
+		case "players":
+			return a.Status.onlineCount() > b.Status.onlineCount()
+		default:
+			return strings.ToLower(a.displayName()) < strings.ToLower(b.displayName())
+		}
+	})
+}
+
+// 在线人数, 服务器未提供人数信息时为 -1 (排序时排在最后)
+func (s *ServerStatus) onlineCount() int {
+	if s.Players == nil {
+		return -1
+	}
+	return s.Players.Online
+}
+
+// 结果的展示名称
+func (r queryResult) displayName() string {
+	if r.Target.Name != "" {
+		return stripLegacyCodes(r.Target.Name)
+	}
+	if r.Host != "" {
+		return r.Host
+	}
+	return r.Target.Address
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const defaultTermWidth = 100 // 无法获取终端宽度时使用的行宽
+
+// 单行摘要的行宽 (来自 COLUMNS 环境变量)
+func termWidth() int {
+	if n, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && n > 0 {
+		return n
+	}
+	return defaultTermWidth
+}
+
+// 字符的显示宽度 (中日韩文字与全角字符占两列)
+func runeWidth(r rune) int {
+	if unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul) ||
+		(r >= 0xFF00 && r <= 0xFF60) || (r >= 0x3000 && r <= 0x303F) {
+		return 2
+	}
+	return 1
+}
+
+// 将文本截断到指定显示宽度, 超出部分以 … 代替
+func truncateWidth(s string, width int) string {
+	used := 0
+	for i, r := range s {
+		w := runeWidth(r)
+		if used+w > width-1 {
+			rest := 0
+			for _, r := range s[i:] {
+				rest += runeWidth(r)
+			}
+			if used+rest <= width {
+				return s
+			}
+			return s[:i] + "…"
+		}
+		used += w
+	}
+	return s
+}
+
+// 生成单行摘要: 地址  版本  在线/最大  延迟  "MOTD" (不含颜色)
+func compactLine(r queryResult) string {
+	addr := r.Target.Address
+	if r.Host != "" {
+		addr = fmt.Sprintf("%s:%d", r.Host, r.Port)
+	}
+	if r.Err != nil {
+		return fmt.Sprintf("%-28s 错误: %s", addr, strings.ReplaceAll(r.Err.Error(), "\n", " "))
+	}
+
+	s := r.Status
+	version := "未知版本"
+	if s.Version != nil {
+		version = truncateWidth(stripLegacyCodes(s.Version.Name), 20)
+	}
+	players := "N/A"
+	if s.Players != nil {
+		players = fmt.Sprintf("%d/%d", s.Players.Online, s.Players.Max)
+	}
+	line := fmt.Sprintf("%-28s %s %9s %6s  ", addr, padRight(version, 20), players, fmt.Sprintf("%dms", s.Ping.Milliseconds()))
+	motd := strings.Join(strings.Fields(toPlainText(s.Description)), " ")
+	room := termWidth() - visibleWidth(line) - 2
+	if room < 10 {
+		room = 10
+	}
+	return line + `"` + truncateWidth(motd, room) + `"`
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// IPv4 与 IPv6 竞速连接时, 首选地址族连接未完成多久后开始尝试另一地址族 (RFC 8305 建议 250ms)
+const happyEyeballsDelay = 250 * time.Millisecond
+
+// 全部查询的截止时间 (--deadline), 超过后连接与读写均会超时; 零值表示不限制
+var queryDeadline time.Time
+
+// 连接的读写截止时间: 取 timeout 与 --deadline 中较早的一个, 均未设置时为零值
+func connDeadline(timeout time.Duration) time.Time {
+	var deadline time.Time
+	if timeout > 0 {
+		deadline = time.Now().Add(timeout)
+	}
+	if !queryDeadline.IsZero() && (deadline.IsZero() || queryDeadline.Before(deadline)) {
+		deadline = queryDeadline
+	}
+	return deadline
+}
+
+// IP 地址族限制
+type ipFamily int
+
+const (
+	familyAny ipFamily = iota
+	familyIPv4
+	familyIPv6
+)
+
+var (
+	dnsFamily  ipFamily // 显示与列出的解析结果 (--ipv4-only-dns / --ipv6-only-dns)
+	dialFamily ipFamily // 连接服务器时使用的地址族 (-4 / -6)
+)
+
+// 按地址族限制网络类型, 如 tcp -> tcp4
+func (f ipFamily) network(network string) string {
+	switch f {
+	case familyIPv4:
+		return network + "4"
+	case familyIPv6:
+		return network + "6"
+	}
+	return network
+}
+
+// 判断 IP 是否属于该地址族
+func (f ipFamily) match(ip string) bool {
+	parsed := net.ParseIP(ip)
+	switch f {
+	case familyIPv4:
+		return parsed != nil && parsed.To4() != nil
+	case familyIPv6:
+		return parsed != nil && parsed.To4() == nil
+	}
+	return true
+}
+
+// 筛选出属于该地址族的 IP
+func (f ipFamily) filter(ips []string) []string {
+	var matched []string
+	for _, ip := range ips {
+		if f.match(ip) {
+			matched = append(matched, ip)
+		}
+	}
+	return matched
+}
+
+// 发起连接时绑定的本地地址 (--source-addr), 为 nil 时由系统选择
+var sourceAddr *net.TCPAddr
+
+// 解析本地源地址, 支持 IP 或 IP:端口
+func parseSourceAddr(s string) (*net.TCPAddr, error) {
+	host, portStr := s, "0"
+	if h, p, err := net.SplitHostPort(s); err == nil {
+		host, portStr = h, p
+	}
+	ip := net.ParseIP(strings.Trim(host, "[]"))
+	if ip == nil {
+		return nil, fmt.Errorf("无效的源地址: %s", s)
+	}
+	port, err := strconv.ParseUint(portStr, 10, 16)
+	if err != nil {
+		return nil, fmt.Errorf("无效的源端口: %s", portStr)
+	}
+	return &net.TCPAddr{IP: ip, Port: int(port)}, nil
+}
+
+// 创建连接服务器所用的 Dialer
+// 域名同时解析出 IPv4 与 IPv6 地址时, 会并行竞速连接两个地址族, 使用先建立的连接
+func newDialer(timeout time.Duration) *net.Dialer {
+	return &net.Dialer{
+		Timeout:       timeout, // 为 0 时直到 TCP 超时
+		Deadline:      queryDeadline,
+		Resolver:      dnsResolver,
+		FallbackDelay: happyEyeballsDelay,
+		LocalAddr:     localAddr(),
+	}
+}
+
+// Dialer 使用的本地地址 (避免将 nil 指针包装为非 nil 接口)
+func localAddr() net.Addr {
+	if sourceAddr == nil {
+		return nil
+	}
+	return sourceAddr
+}
+
+// 连接到服务器 (指定了 --proxy 时经由代理连接), 并返回 TCP 连接的建立耗时 (从发起连接到连接建立, 不含域名解析)
+// 使用代理时为连接代理并完成代理握手的总耗时
+func dialServerTimed(host string, port uint16, timeout time.Duration) (net.Conn, time.Duration, error) {
+	address := net.JoinHostPort(host, strconv.Itoa(int(port)))
+	diagLog.Debug("开始连接", "addr", address, "proxy", proxyURL != nil)
+	if proxyURL != nil {
+		start := time.Now()
+		conn, err := dialProxy(proxyURL, address, timeout)
+		if err != nil {
+			diagLog.Error("经由代理连接失败", "addr", address, "elapsed", time.Since(start), "err", err)
+			return nil, 0, err
+		}
+		diagLog.Info("已经由代理建立连接", "addr", address, "elapsed", time.Since(start))
+		return conn, time.Since(start), nil
+	}
+
+	// 竞速连接时会同时连接多个地址, 按地址记录各自发起连接的时间, 以建立的连接为准
+	var starts sync.Map
+	dialer := newDialer(timeout)
+	dialer.Control = func(network, address string, c syscall.RawConn) error {
+		starts.Store(address, time.Now())
+		return nil
+	}
+	dialStart := time.Now()
+	conn, err := dialer.Dial(dialFamily.network("tcp"), address)
+	if err != nil {
+		diagLog.Error("连接失败", "addr", address, "elapsed", time.Since(dialStart), "err", err)
+		return nil, 0, err
+	}
+	var rtt time.Duration
+	if start, ok := starts.Load(conn.RemoteAddr().String()); ok {
+		rtt = time.Since(start.(time.Time))
+	}
+	diagLog.Info("连接已建立", "addr", address, "remote", conn.RemoteAddr().String(), "tcp_rtt", rtt, "elapsed", time.Since(dialStart))
+	return conn, rtt, nil
+}
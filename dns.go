@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+const dnsCacheTTL = 30 * time.Second // DNS 查询结果缓存时间
+
+// 缓存的 DNS 查询结果 (包括失败结果, 避免重复查询不存在的 SRV 记录)
+type dnsCacheEntry struct {
+	srv     []*net.SRV
+	hosts   []string
+	err     error
+	expires time.Time
+}
+
+// 用于 SRV/A/AAAA 查询及连接时解析域名的解析器 (可通过 --dns 指定)
+var dnsResolver = net.DefaultResolver
+
+// 使用指定的 DNS 服务器进行解析 (未指定端口时默认使用 53)
+func setDNSServer(server string) {
+	if _, _, err := net.SplitHostPort(server); err != nil {
+		server = net.JoinHostPort(strings.Trim(server, "[]"), "53")
+	}
+	dialer := net.Dialer{Timeout: 5 * time.Second}
+	dnsResolver = &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, address string) (net.Conn, error) {
+			return dialer.DialContext(ctx, network, server)
+		},
+	}
+}
+
+var (
+	dnsCacheMu sync.Mutex
+	dnsCache   = map[string]dnsCacheEntry{}
+)
+
+// 读取未过期的缓存条目
+func getDNSCache(key string) (dnsCacheEntry, bool) {
+	dnsCacheMu.Lock()
+	defer dnsCacheMu.Unlock()
+	entry, ok := dnsCache[key]
+	if !ok || time.Now().After(entry.expires) {
+		return dnsCacheEntry{}, false
+	}
+	return entry, true
+}
+
+// 写入缓存条目
+func putDNSCache(key string, entry dnsCacheEntry) {
+	entry.expires = time.Now().Add(dnsCacheTTL)
+	dnsCacheMu.Lock()
+	dnsCache[key] = entry
+	dnsCacheMu.Unlock()
+}
+
+// 带缓存的 Minecraft SRV 记录查询
+func lookupSRVCached(name string) ([]*net.SRV, error) {
+	key := "srv:" + name
+	if entry, ok := getDNSCache(key); ok {
+		return entry.srv, entry.err
+	}
+	_, addrs, err := dnsResolver.LookupSRV(context.Background(), "minecraft", "tcp", name)
+	putDNSCache(key, dnsCacheEntry{srv: addrs, err: err})
+	return addrs, err
+}
+
+// 带缓存的 A/AAAA 记录查询
+func lookupHostCached(host string) ([]string, error) {
+	key := "host:" + host
+	if entry, ok := getDNSCache(key); ok {
+		return entry.hosts, entry.err
+	}
+	hosts, err := dnsResolver.LookupHost(context.Background(), host)
+	putDNSCache(key, dnsCacheEntry{hosts: hosts, err: err})
+	return hosts, err
+}
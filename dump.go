@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+const dumpMaxString = 64 // 输出结构时字符串的最大长度, 超出部分省略 (如 favicon)
+
+// 以缩进格式输出解析后的结构体 (--dump-struct), 用于检查解析结果
+// 与原始 JSON 不同, 会显示全部字段 (包括零值、nil 与不参与 JSON 序列化的字段)
+func dumpStruct(w io.Writer, v interface{}) {
+	var b strings.Builder
+	dumpValue(&b, reflect.ValueOf(v), 0)
+	fmt.Fprintln(w, b.String())
+}
+
+func dumpValue(b *strings.Builder, v reflect.Value, depth int) {
+	indent := strings.Repeat("    ", depth)
+	if !v.IsValid() {
+		b.WriteString("nil")
+		return
+	}
+	// 未导出字段 (如 pingError.err) 不能调用 Interface, 仅按类型逐层输出
+	if s, ok := interfaceOf(v).(fmt.Stringer); ok && v.Kind() != reflect.Ptr && v.Kind() != reflect.Struct {
+		b.WriteString(s.String()) // 如 time.Duration
+		return
+	}
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			b.WriteString("nil")
+			return
+		}
+		dumpValue(b, v.Elem(), depth)
+	case reflect.Struct:
+		if v.Type().Name() != "" {
+			b.WriteString(v.Type().String() + " ")
+		}
+		b.WriteString("{\n")
+		for i := 0; i < v.NumField(); i++ {
+			b.WriteString(indent + "    " + v.Type().Field(i).Name + ": ")
+			dumpValue(b, v.Field(i), depth+1)
+			b.WriteString("\n")
+		}
+		b.WriteString(indent + "}")
+	case reflect.Slice, reflect.Array:
+		if v.Len() == 0 {
+			b.WriteString("[]")
+			return
+		}
+		b.WriteString("[\n")
+		for i := 0; i < v.Len(); i++ {
+			b.WriteString(indent + "    ")
+			dumpValue(b, v.Index(i), depth+1)
+			b.WriteString("\n")
+		}
+		b.WriteString(indent + "]")
+	case reflect.Map:
+		if v.Len() == 0 {
+			b.WriteString("{}")
+			return
+		}
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool { return fmt.Sprint(keys[i]) < fmt.Sprint(keys[j]) })
+		b.WriteString("{\n")
+		for _, k := range keys {
+			b.WriteString(fmt.Sprintf("%s    %v: ", indent, k))
+			dumpValue(b, v.MapIndex(k), depth+1)
+			b.WriteString("\n")
+		}
+		b.WriteString(indent + "}")
+	case reflect.String:
+		s := v.String()
+		if len(s) > dumpMaxString {
+			b.WriteString(fmt.Sprintf("%q... (共 %d 字节)", s[:dumpMaxString], len(s)))
+			return
+		}
+		b.WriteString(fmt.Sprintf("%q", s))
+	default:
+		b.WriteString(fmt.Sprintf("%v", v))
+	}
+}
+
+// 可以取值时返回 v 的值, 否则返回 nil
+func interfaceOf(v reflect.Value) interface{} {
+	if !v.CanInterface() {
+		return nil
+	}
+	return v.Interface()
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 时长参数: 纯数字按秒计算 (兼容旧用法), 也可使用 Go 时长格式 (如 500ms, 1.5s)
+type durationFlag time.Duration
+
+func (d *durationFlag) String() string {
+	return time.Duration(*d).String()
+}
+
+func (d *durationFlag) Set(s string) error {
+	v, err := parseDurationArg(s)
+	if err != nil {
+		return err
+	}
+	*d = durationFlag(v)
+	return nil
+}
+
+// 解析时长参数
+func parseDurationArg(s string) (time.Duration, error) {
+	var v time.Duration
+	if n, err := strconv.ParseFloat(s, 64); err == nil {
+		v = time.Duration(n * float64(time.Second))
+	} else if v, err = time.ParseDuration(s); err != nil {
+		return 0, fmt.Errorf("无效的时长: %s", s)
+	}
+	if v < 0 {
+		return 0, fmt.Errorf("时长不能为负数: %s", s)
+	}
+	return v, nil
+}
+
+// 根据 IPv4 / IPv6 限制参数得到地址族
+func familyFlag(ipv4, ipv6 bool) ipFamily {
+	switch {
+	case ipv4:
+		return familyIPv4
+	case ipv6:
+		return familyIPv6
+	}
+	return familyAny
+}
+
+// Forge 握手标记参数: 单独使用 --fml 表示 FML2 (1.13 - 1.17), 也可指定 --fml=1 (1.7 - 1.12) 或 --fml=3 (1.18+)
+type fmlFlag string
+
+func (f *fmlFlag) IsBoolFlag() bool { return true }
+
+func (f *fmlFlag) String() string {
+	return strings.Trim(string(*f), "\x00")
+}
+
+func (f *fmlFlag) Set(s string) error {
+	switch s {
+	case "true", "2":
+		*f = "\x00FML2\x00"
+	case "1":
+		*f = "\x00FML\x00"
+	case "3":
+		*f = "\x00FML3\x00"
+	case "false":
+		*f = ""
+	default:
+		return fmt.Errorf("无效的 FML 版本: %s (可选: 1, 2, 3)", s)
+	}
+	return nil
+}
+
+// 状态中可要求存在的字段 (--retry-on-empty)
+var statusFieldNames = []string{"version", "players", "description", "favicon"}
+
+// 空状态判定参数: 单独使用 --retry-on-empty 表示要求包含 version 字段, 也可指定 --retry-on-empty=version,players
+type requiredFieldsFlag []string
+
+func (f *requiredFieldsFlag) IsBoolFlag() bool { return true }
+
+func (f *requiredFieldsFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *requiredFieldsFlag) Set(s string) error {
+	switch s {
+	case "true":
+		*f = requiredFieldsFlag{"version"}
+		return nil
+	case "false":
+		*f = nil
+		return nil
+	}
+	var fields requiredFieldsFlag
+	for _, name := range strings.Split(s, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if !slices.Contains(statusFieldNames, name) {
+			return fmt.Errorf("无效的状态字段: %q (可选: %s)", name, strings.Join(statusFieldNames, ", "))
+		}
+		fields = append(fields, name)
+	}
+	*f = fields
+	return nil
+}
+
+// 从环境变量读取回退端口与 --timeout 的默认值, 需在解析命令行参数之前调用
+// MOTD_DEFAULT_PORT 不会写入 --port, 因此不参与端口冲突检查, 也不会跳过 SRV 查询
+func applyEnvDefaults(timeout *durationFlag) error {
+	if v := os.Getenv("MOTD_DEFAULT_PORT"); v != "" {
+		p, err := strconv.Atoi(v)
+		if err != nil || p < 1 || p > 65535 {
+			return fmt.Errorf("环境变量 MOTD_DEFAULT_PORT 无效: %s", v)
+		}
+		fallbackPort = uint16(p)
+	}
+	if v := os.Getenv("MOTD_TIMEOUT"); v != "" {
+		if err := timeout.Set(v); err != nil {
+			return fmt.Errorf("环境变量 MOTD_TIMEOUT 无效: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// ForgeModInfo 表示旧版 Forge (1.7 - 1.12) 状态响应中的 modinfo 字段
+type ForgeModInfo struct {
+	Type    string     `json:"type"` // 通常为 FML
+	ModList []ForgeMod `json:"modList"`
+}
+
+// ForgeMod 表示 modinfo.modList 中的一个模组
+type ForgeMod struct {
+	ModID   string `json:"modid"`
+	Version string `json:"version"`
+}
+
+// 判断状态响应是否为新版 Forge (1.13+) 的 forgeData 格式
+func hasForgeData(raw string) bool {
+	var fields map[string]json.RawMessage
+	if json.Unmarshal([]byte(raw), &fields) != nil {
+		return false
+	}
+	_, ok := fields["forgeData"]
+	return ok
+}
+
+// 打印模组信息: 默认仅显示模组数量, listMods 为 true 时逐行列出模组与版本
+func printForgeMods(w io.Writer, data *ServerStatus, listMods bool) {
+	info := data.ModInfo
+	if info == nil {
+		if hasForgeData(data.Raw) {
+			fmt.Fprintln(w, "模组: "+colorize("新版 Forge (forgeData) 服务器", "gray"))
+		}
+		return
+	}
+
+	fmt.Fprintf(w, "模组: %d 个 %s\n", len(info.ModList), colorize(fmt.Sprintf("(旧版 Forge modinfo, 类型 %s)", cmp.Or(info.Type, "未知")), "gray"))
+	if !listMods {
+		return
+	}
+	width := 0
+	for _, mod := range info.ModList {
+		width = max(width, len(mod.ModID))
+	}
+	for _, mod := range info.ModList {
+		fmt.Fprintf(w, "    %-*s  %s\n", width, mod.ModID, mod.Version)
+	}
+}
@@ -1,3 +1,8 @@
 module mc-motd
 
 go 1.24.3
+
+require (
+	golang.org/x/net v0.46.0
+	golang.org/x/text v0.30.0
+)
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+	"net"
+	"os"
+	"sync"
+)
+
+// 将收发的原始字节以十六进制输出到标准错误 (--hexdump)
+var hexdumpEnabled bool
+
+var hexdumpMu sync.Mutex // 并发查询时避免多个连接的输出交错
+
+// 记录收发数据的连接
+type hexdumpConn struct {
+	net.Conn
+}
+
+func (c *hexdumpConn) Read(b []byte) (int, error) {
+	n, err := c.Conn.Read(b)
+	if n > 0 {
+		c.dump("接收", b[:n])
+	}
+	return n, err
+}
+
+func (c *hexdumpConn) Write(b []byte) (int, error) {
+	c.dump("发送", b)
+	return c.Conn.Write(b)
+}
+
+func (c *hexdumpConn) dump(direction string, b []byte) {
+	hexdumpMu.Lock()
+	defer hexdumpMu.Unlock()
+	fmt.Fprintf(os.Stderr, "%s %d 字节 (%s):\n%s", direction, len(b), c.RemoteAddr(), hex.Dump(b))
+}
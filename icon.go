@@ -0,0 +1,167 @@
+package main
+
+import (
+	"bytes"
+	"cmp"
+	"compress/gzip"
+	"crypto/sha256"
+	_ "embed"
+	"encoding/hex"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"os"
+	"strings"
+)
+
+const (
+	maxFaviconSize = 1 << 20 // 解码后图标大小上限, 防止异常服务器返回超大数据
+	faviconSide    = 64      // 规范要求的图标边长 (64x64 PNG)
+)
+
+// 解码状态 JSON 中的 favicon (data:image/png;base64,...)
+// 部分服务端会发送 gzip 压缩的数据, 此时自动解压; 超过大小上限时返回错误
+func decodeFavicon(favicon string) ([]byte, error) {
+	if _, data, ok := strings.Cut(favicon, ";base64,"); ok && strings.HasPrefix(favicon, "data:") {
+		favicon = data
+	}
+	if len(favicon)/4*3 > maxFaviconSize {
+		return nil, fmt.Errorf("图标过大 (超过 %d KB)", maxFaviconSize>>10)
+	}
+	decoded, err := decodeBase64(favicon)
+	if err != nil {
+		return nil, err
+	}
+	if len(decoded) >= 2 && decoded[0] == 0x1f && decoded[1] == 0x8b {
+		gz, err := gzip.NewReader(bytes.NewReader(decoded))
+		if err != nil {
+			return nil, err
+		}
+		defer gz.Close()
+		if decoded, err = io.ReadAll(io.LimitReader(gz, maxFaviconSize+1)); err != nil {
+			return nil, err
+		}
+		if len(decoded) > maxFaviconSize {
+			return nil, fmt.Errorf("图标过大 (解压后超过 %d KB)", maxFaviconSize>>10)
+		}
+	}
+	return decoded, nil
+}
+
+// 检查图标是否为规范的 64x64 PNG, 返回不符合之处的说明 (符合规范时为空)
+func faviconWarning(decoded []byte) string {
+	config, format, err := image.DecodeConfig(bytes.NewReader(decoded))
+	switch {
+	case err != nil:
+		return "无法识别的图片格式"
+	case format != "png":
+		return "格式为 " + format + ", 不是 PNG"
+	case config.Width != faviconSide || config.Height != faviconSide:
+		return fmt.Sprintf("非标准尺寸 %dx%d, 应为 64x64", config.Width, config.Height)
+	}
+	return ""
+}
+
+// 服务器图标的状态
+type faviconKind int
+
+const (
+	faviconNone    faviconKind = iota // 未返回 favicon (未设置 server-icon.png), 客户端显示内置的占位图标
+	faviconDefault                    // 与已知的默认 server-icon.png 相同
+	faviconCustom                     // 自定义图标 (包括无法解码的图标)
+)
+
+// 内置的默认 server-icon.png 列表, 可使用 --default-icons 补充
+//
+//go:embed default_icons.txt
+var embeddedDefaultIcons []byte
+
+// 已知默认 server-icon.png 的 SHA-256 (解码后的 PNG 内容) 与其来源, 如开服面板或整合包自带的图标
+var defaultFaviconHashes = mustParseDefaultFavicons(embeddedDefaultIcons, "内置列表")
+
+// 解析默认图标列表, 每行为 "<SHA-256> [来源]", # 开头为注释; 未注明来源时使用 source
+func parseDefaultFavicons(data []byte, source string) (map[string]string, error) {
+	hashes := map[string]string{}
+	for i, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		hash, from, _ := strings.Cut(line, " ")
+		hash = strings.ToLower(hash)
+		if b, err := hex.DecodeString(hash); err != nil || len(b) != sha256.Size {
+			return nil, fmt.Errorf("第 %d 行: 无效的 SHA-256: %s", i+1, hash)
+		}
+		hashes[hash] = cmp.Or(strings.TrimSpace(from), source)
+	}
+	return hashes, nil
+}
+
+func mustParseDefaultFavicons(data []byte, source string) map[string]string {
+	hashes, err := parseDefaultFavicons(data, source)
+	if err != nil {
+		panic(err)
+	}
+	return hashes
+}
+
+// 从文本文件加载默认图标的哈希 (--default-icons), 同一哈希以文件为准
+func loadDefaultFavicons(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	hashes, err := parseDefaultFavicons(data, "自定义列表")
+	if err != nil {
+		return err
+	}
+	for hash, source := range hashes {
+		defaultFaviconHashes[hash] = source
+	}
+	return nil
+}
+
+// 解码后图标内容的 SHA-256 (十六进制)
+func faviconHash(decoded []byte) string {
+	sum := sha256.Sum256(decoded)
+	return hex.EncodeToString(sum[:])
+}
+
+// 判断图标状态, 为默认图标时同时返回其来源
+func classifyFavicon(favicon string) (faviconKind, string) {
+	if favicon == "" {
+		return faviconNone, ""
+	}
+	decoded, err := decodeFavicon(favicon)
+	if err != nil {
+		return faviconCustom, ""
+	}
+	if source, ok := defaultFaviconHashes[faviconHash(decoded)]; ok {
+		return faviconDefault, source
+	}
+	return faviconCustom, ""
+}
+
+// 图标信息的展示文本
+func describeFavicon(favicon string) string {
+	if favicon == "" {
+		return "未设置 (服务器未返回图标, 客户端显示默认图标)"
+	}
+	decoded, err := decodeFavicon(favicon)
+	if err != nil {
+		return "自定义 (解码失败: " + err.Error() + ")"
+	}
+	// 图标内容的 SHA-256, 用于在批量查询中识别相同的图标
+	hash := faviconHash(decoded)
+	desc := "自定义 (SHA-256: " + hash[:12]
+	if source, ok := defaultFaviconHashes[hash]; ok {
+		desc = colorize("默认图标", "yellow") + " (" + source + ", SHA-256: " + hash[:12]
+	}
+	if warning := faviconWarning(decoded); warning != "" {
+		desc += ", " + warning
+	}
+	return desc + ")"
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"image"
+	"image/png"
+	"maps"
+	"testing"
+)
+
+// 生成 64x64 的 PNG 图标, 返回状态 JSON 中的 favicon 字符串与解码后的内容
+func testFavicon(t *testing.T) (string, []byte) {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, faviconSide, faviconSide))); err != nil {
+		t.Fatal(err)
+	}
+	return "data:image/png;base64," + base64.StdEncoding.EncodeToString(buf.Bytes()), buf.Bytes()
+}
+
+func TestClassifyFavicon(t *testing.T) {
+	favicon, decoded := testFavicon(t)
+	list := []byte("# 测试\n" + faviconHash(decoded) + " 测试面板\n")
+	hashes, err := parseDefaultFavicons(list, "内置列表")
+	if err != nil {
+		t.Fatalf("parseDefaultFavicons() error = %v", err)
+	}
+	saved := maps.Clone(defaultFaviconHashes)
+	t.Cleanup(func() { defaultFaviconHashes = saved })
+	maps.Copy(defaultFaviconHashes, hashes)
+
+	tests := []struct {
+		name       string
+		favicon    string
+		wantKind   faviconKind
+		wantSource string
+	}{
+		{"未设置图标", "", faviconNone, ""},
+		{"默认图标", favicon, faviconDefault, "测试面板"},
+		{"自定义图标", "data:image/png;base64," + base64.StdEncoding.EncodeToString([]byte("custom")), faviconCustom, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kind, source := classifyFavicon(tt.favicon)
+			if kind != tt.wantKind || source != tt.wantSource {
+				t.Errorf("classifyFavicon() = %v, %q, want %v, %q", kind, source, tt.wantKind, tt.wantSource)
+			}
+
+			out, err := json.Marshal(queryResult{Status: &ServerStatus{Favicon: tt.favicon}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got struct {
+				DefaultIcon bool `json:"default_icon"`
+				NoIcon      bool `json:"no_icon"`
+			}
+			json.Unmarshal(out, &got)
+			if got.DefaultIcon != (tt.wantKind == faviconDefault) || got.NoIcon != (tt.wantKind == faviconNone) {
+				t.Errorf("JSON default_icon = %v, no_icon = %v, kind %v", got.DefaultIcon, got.NoIcon, tt.wantKind)
+			}
+		})
+	}
+}
+
+func TestParseDefaultFavicons(t *testing.T) {
+	if _, err := parseDefaultFavicons(embeddedDefaultIcons, "内置列表"); err != nil {
+		t.Errorf("内置默认图标列表无效: %v", err)
+	}
+	if _, err := parseDefaultFavicons([]byte("zz 无效\n"), "内置列表"); err == nil {
+		t.Error("parseDefaultFavicons() 应拒绝无效的哈希")
+	}
+	hashes, err := parseDefaultFavicons([]byte("ABCDEF0123456789abcdef0123456789ABCDEF0123456789abcdef0123456789\n"), "内置列表")
+	if err != nil || hashes["abcdef0123456789abcdef0123456789abcdef0123456789abcdef0123456789"] != "内置列表" {
+		t.Errorf("parseDefaultFavicons() = %v, %v, 应转换为小写并使用默认来源", hashes, err)
+	}
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"os"
+	"strings"
+)
+
+// 终端内显示图标的方式 (--icon-protocol)
+var iconProtocols = []string{"auto", "iterm", "kitty", "blocks"}
+
+// 根据 TERM / TERM_PROGRAM 推测终端支持的图片协议, 无法识别时使用半块字符
+func detectIconProtocol() string {
+	switch {
+	case os.Getenv("KITTY_WINDOW_ID") != "", strings.Contains(os.Getenv("TERM"), "kitty"):
+		return "kitty"
+	case os.Getenv("TERM_PROGRAM") == "iTerm.app", os.Getenv("TERM_PROGRAM") == "WezTerm":
+		return "iterm"
+	}
+	return "blocks"
+}
+
+// 在终端中显示解码后的图标
+func printIcon(w io.Writer, decoded []byte, protocol string) error {
+	if protocol == "auto" {
+		protocol = detectIconProtocol()
+	}
+	switch protocol {
+	case "iterm":
+		// iTerm2 内联图片: OSC 1337 ; File=[参数] : base64 数据 BEL
+		fmt.Fprintf(w, "\033]1337;File=inline=1;size=%d;preserveAspectRatio=1:%s\a\n", len(decoded), base64.StdEncoding.EncodeToString(decoded))
+		return nil
+	case "kitty":
+		return printKittyIcon(w, decoded)
+	}
+	img, _, err := image.Decode(bytes.NewReader(decoded))
+	if err != nil {
+		return err
+	}
+	printBlockIcon(w, img)
+	return nil
+}
+
+// Kitty 图形协议: 仅接受 PNG (f=100), 数据按 4096 字节分块发送, m=1 表示后续还有数据
+func printKittyIcon(w io.Writer, decoded []byte) error {
+	if _, format, err := image.DecodeConfig(bytes.NewReader(decoded)); err != nil {
+		return err
+	} else if format != "png" {
+		img, _, err := image.Decode(bytes.NewReader(decoded))
+		if err != nil {
+			return err
+		}
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, img); err != nil {
+			return err
+		}
+		decoded = buf.Bytes()
+	}
+
+	data := base64.StdEncoding.EncodeToString(decoded)
+	for first := true; data != ""; first = false {
+		chunk := data[:min(len(data), 4096)]
+		data = data[len(chunk):]
+		more := 0
+		if data != "" {
+			more = 1
+		}
+		if first {
+			fmt.Fprintf(w, "\033_Ga=T,f=100,m=%d;%s\033\\", more, chunk)
+		} else {
+			fmt.Fprintf(w, "\033_Gm=%d;%s\033\\", more, chunk)
+		}
+	}
+	fmt.Fprintln(w)
+	return nil
+}
+
+const blockIconWidth = 32 // 半块字符显示时的宽度 (列), 每行字符显示上下两个像素
+
+// 使用半块字符 (▀) 与真彩色显示图标, 前景色为上方像素, 背景色为下方像素, 透明像素显示终端背景
+func printBlockIcon(w io.Writer, img image.Image) {
+	bounds := img.Bounds()
+	step := max(1, bounds.Dx()/blockIconWidth)
+	pixel := func(x, y int) ([3]uint8, bool) {
+		if y >= bounds.Max.Y {
+			return [3]uint8{}, false
+		}
+		r, g, b, a := img.At(x, y).RGBA()
+		if a < 0x8000 {
+			return [3]uint8{}, false
+		}
+		return [3]uint8{uint8(r >> 8), uint8(g >> 8), uint8(b >> 8)}, true
+	}
+
+	var sb strings.Builder
+	for y := bounds.Min.Y; y < bounds.Max.Y; y += step * 2 {
+		for x := bounds.Min.X; x < bounds.Max.X; x += step {
+			top, topOK := pixel(x, y)
+			bottom, bottomOK := pixel(x, y+step)
+			switch {
+			case topOK && bottomOK:
+				fmt.Fprintf(&sb, "\033[38;2;%d;%d;%dm\033[48;2;%d;%d;%dm▀", top[0], top[1], top[2], bottom[0], bottom[1], bottom[2])
+			case topOK:
+				fmt.Fprintf(&sb, "\033[49m\033[38;2;%d;%d;%dm▀", top[0], top[1], top[2])
+			case bottomOK:
+				fmt.Fprintf(&sb, "\033[49m\033[38;2;%d;%d;%dm▄", bottom[0], bottom[1], bottom[2])
+			default:
+				sb.WriteString(ansiReset + " ")
+			}
+		}
+		sb.WriteString(ansiReset + "\n")
+	}
+	io.WriteString(w, sb.String())
+}
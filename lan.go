@@ -0,0 +1,116 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 游戏"对局域网开放"时广播的组播地址
+var lanMulticastAddr = &net.UDPAddr{IP: net.IPv4(224, 0, 2, 60), Port: 4445}
+
+// 局域网中发现的世界
+type lanWorld struct {
+	MOTD    string
+	Address string // ip:port
+}
+
+// 解析局域网广播数据: [MOTD]...[/MOTD][AD]port[/AD]
+func parseLANBroadcast(payload string, sender net.IP) (lanWorld, bool) {
+	motd, ok := extractBetween(payload, "[MOTD]", "[/MOTD]")
+	if !ok {
+		return lanWorld{}, false
+	}
+	ad, ok := extractBetween(payload, "[AD]", "[/AD]")
+	if !ok {
+		return lanWorld{}, false
+	}
+
+	// 新版本仅广播端口, 部分旧版本会广播 ip:port
+	host, portStr := sender.String(), ad
+	if h, p, err := net.SplitHostPort(ad); err == nil {
+		portStr = p
+		if h != "" && h != "0.0.0.0" {
+			host = h
+		}
+	}
+	if _, err := strconv.ParseUint(portStr, 10, 16); err != nil {
+		return lanWorld{}, false
+	}
+	return lanWorld{MOTD: motd, Address: net.JoinHostPort(host, portStr)}, true
+}
+
+// 提取 start 与 end 标记之间的内容
+func extractBetween(s, start, end string) (string, bool) {
+	i := strings.Index(s, start)
+	if i < 0 {
+		return "", false
+	}
+	s = s[i+len(start):]
+	j := strings.Index(s, end)
+	if j < 0 {
+		return "", false
+	}
+	return s[:j], true
+}
+
+// 在指定时间内监听局域网广播, 返回发现的世界 (按发现顺序, 已去重)
+func discoverLANWorlds(duration time.Duration) ([]lanWorld, error) {
+	conn, err := net.ListenMulticastUDP("udp4", nil, lanMulticastAddr)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(duration))
+
+	var worlds []lanWorld
+	seen := map[string]bool{}
+	buf := make([]byte, 2048)
+	for {
+		n, sender, err := conn.ReadFromUDP(buf)
+		if err != nil {
+			if ne, ok := err.(net.Error); ok && ne.Timeout() {
+				return worlds, nil
+			}
+			return worlds, err
+		}
+		world, ok := parseLANBroadcast(string(buf[:n]), sender.IP)
+		if !ok || seen[world.Address] {
+			continue
+		}
+		seen[world.Address] = true
+		worlds = append(worlds, world)
+	}
+}
+
+// 局域网发现模式: 列出发现的世界, query 为 true 时逐个查询其 MOTD
+func runLANMode(w io.Writer, duration time.Duration, query bool, opts queryOptions, display displayOptions, batch batchOptions) int {
+	if !display.Quiet {
+		fmt.Fprintf(w, "正在监听局域网中开放的世界 (%s)...\n", duration)
+	}
+	worlds, err := discoverLANWorlds(duration)
+	if err != nil {
+		fmt.Fprintln(w, "局域网监听失败:", err)
+		return 1
+	}
+	if len(worlds) == 0 {
+		fmt.Fprintln(w, "\n未发现局域网世界")
+		return 0
+	}
+
+	fmt.Fprintf(w, "\n发现 %d 个局域网世界:\n", len(worlds))
+	targets := make([]Target, 0, len(worlds))
+	for _, world := range worlds {
+		fmt.Fprintf(w, "    %s  %s\n", world.Address, renderLegacy(world.MOTD))
+		targets = append(targets, Target{Name: world.MOTD, Address: world.Address})
+	}
+
+	if !query {
+		return 0
+	}
+	fmt.Fprintln(w)
+	return runBatchMode(w, targets, opts, display, batch)
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// 协议各步骤的诊断日志 (--log-level), 默认不输出
+var diagLog = slog.New(slog.DiscardHandler)
+
+// 按 --log-level 启用诊断日志, 输出到标准错误 (不影响标准输出中的查询结果)
+// debug: 每个协议步骤; info: 连接建立、收到状态与 pong 等关键步骤及耗时; error: 仅失败原因
+func setLogLevel(s string) error {
+	var level slog.Level
+	switch strings.ToLower(s) {
+	case "debug":
+		level = slog.LevelDebug
+	case "info":
+		level = slog.LevelInfo
+	case "error":
+		level = slog.LevelError
+	default:
+		return fmt.Errorf("无效的日志级别: %s (可选 debug / info / error)", s)
+	}
+	diagLog = slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: level}))
+	return nil
+}
+
+// 是否向标准错误输出诊断信息 (--log-level / --hexdump), 此时不显示会与之交错的进度指示
+func diagnosticsEnabled() bool {
+	return hexdumpEnabled || diagLog.Handler() != slog.DiscardHandler
+}
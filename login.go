@@ -0,0 +1,235 @@
+package main
+
+import (
+	"bytes"
+	"cmp"
+	"compress/zlib"
+	"crypto/md5"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"time"
+)
+
+// 登录阶段的数据包 ID (服务端 -> 客户端)
+const (
+	loginDisconnect     = 0x00
+	loginEncryption     = 0x01
+	loginSuccess        = 0x02
+	loginSetCompression = 0x03
+	loginPluginRequest  = 0x04
+)
+
+const maxLoginPackets = 16 // 登录探测最多处理的数据包数量
+
+var usernamePattern = regexp.MustCompile(`^[A-Za-z0-9_]{1,16}$`)
+
+// 登录探测结果
+type loginProbe struct {
+	Disconnect string // 服务器断开连接的原因 (原始 JSON 文本组件)
+	Outcome    string // 未被断开连接时的结果说明
+}
+
+// 校验离线模式的用户名
+func validateUsername(name string) error {
+	if !usernamePattern.MatchString(name) {
+		return fmt.Errorf("无效的用户名: %q (只能包含字母、数字与下划线, 长度 1-16)", name)
+	}
+	return nil
+}
+
+// 以指定用户名尝试登录, 获取服务器的断开连接原因 (如白名单、封禁、服务器已满)
+// protocol 应为服务器状态中报告的协议版本, 登录开始包的格式随版本不同
+// handshake 为握手包中声明的主机名, 为空时使用实际连接的主机名
+func probeLogin(host string, port uint16, timeout time.Duration, handshake string, protocol int, username string) (*loginProbe, error) {
+	conn, err := connectServer(host, port, timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if err := writeHandshakeState(conn, cmp.Or(handshake, host), port, protocol, 2); err != nil {
+		return nil, err
+	}
+	if _, err := conn.Write(loginStartPacket(protocol, username)); err != nil {
+		return nil, err
+	}
+
+	threshold := -1 // 未启用压缩
+	for i := 0; i < maxLoginPackets; i++ {
+		id, data, err := readLoginPacket(conn, threshold)
+		if err != nil {
+			return nil, err
+		}
+		buf := bytes.NewBuffer(data)
+		switch id {
+		case loginDisconnect:
+			reason, err := readString(buf)
+			if err != nil {
+				return nil, err
+			}
+			return &loginProbe{Disconnect: reason}, nil
+		case loginEncryption:
+			return &loginProbe{Outcome: "服务器要求加密 (正版验证模式), 无法在不登录账号的情况下继续探测"}, nil
+		case loginSuccess:
+			return &loginProbe{Outcome: "登录成功, 服务器允许该用户名进入 (离线模式)"}, nil
+		case loginSetCompression:
+			if threshold, err = readVarInt(buf); err != nil {
+				return nil, err
+			}
+		case loginPluginRequest:
+			// 回复 "不理解该请求", 由服务器决定是否继续
+			messageID, err := readVarInt(buf)
+			if err != nil {
+				return nil, err
+			}
+			var resp bytes.Buffer
+			resp.WriteByte(0x02)
+			writeVarInt(&resp, messageID)
+			resp.WriteByte(0x00)
+			if err := writeLoginPacket(conn, resp.Bytes(), threshold); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, fmt.Errorf("收到未知的登录数据包, ID %d", id)
+		}
+	}
+	return nil, errors.New("服务器发送的登录数据包过多")
+}
+
+// 构造登录开始包
+// 1.19 (759) 起追加签名数据标记, 1.19.1 (760) 起可选附带 UUID, 1.19.3 (761) 起移除签名数据, 1.20.2 (764) 起 UUID 为必需字段
+func loginStartPacket(protocol int, username string) []byte {
+	var body bytes.Buffer
+	body.WriteByte(0x00)
+	writeVarInt(&body, len(username))
+	body.WriteString(username)
+	uuid := offlineUUID(username)
+	switch {
+	case protocol >= 764:
+		body.Write(uuid[:])
+	case protocol >= 761:
+		body.WriteByte(0x01)
+		body.Write(uuid[:])
+	case protocol == 760:
+		body.WriteByte(0x00) // 无签名数据
+		body.WriteByte(0x01)
+		body.Write(uuid[:])
+	case protocol == 759:
+		body.WriteByte(0x00) // 无签名数据
+	}
+
+	var packet bytes.Buffer
+	writeVarInt(&packet, body.Len())
+	packet.Write(body.Bytes())
+	return packet.Bytes()
+}
+
+// 离线模式下玩家的 UUID (基于 "OfflinePlayer:<用户名>" 的 MD5, 版本 3)
+func offlineUUID(username string) [16]byte {
+	uuid := md5.Sum([]byte("OfflinePlayer:" + username))
+	uuid[6] = uuid[6]&0x0f | 0x30
+	uuid[8] = uuid[8]&0x3f | 0x80
+	return uuid
+}
+
+// 读取一个登录阶段的数据包, threshold >= 0 时按压缩格式读取
+func readLoginPacket(r io.Reader, threshold int) (int, []byte, error) {
+	length, err := readVarInt(r)
+	if err != nil {
+		return 0, nil, err
+	}
+	if length <= 0 || length > maxPacketLength {
+		return 0, nil, fmt.Errorf("登录数据包长度无效: %d", length)
+	}
+	data := make([]byte, length)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return 0, nil, err
+	}
+
+	if threshold >= 0 {
+		buf := bytes.NewBuffer(data)
+		dataLen, err := readVarInt(buf)
+		if err != nil {
+			return 0, nil, err
+		}
+		if dataLen > 0 {
+			zr, err := zlib.NewReader(buf)
+			if err != nil {
+				return 0, nil, err
+			}
+			data, err = io.ReadAll(io.LimitReader(zr, int64(dataLen)))
+			zr.Close()
+			if err != nil {
+				return 0, nil, err
+			}
+		} else {
+			data = buf.Bytes()
+		}
+	}
+
+	buf := bytes.NewBuffer(data)
+	id, err := readVarInt(buf)
+	if err != nil {
+		return 0, nil, err
+	}
+	return id, buf.Bytes(), nil
+}
+
+// 发送一个登录阶段的数据包 (启用压缩后, 小于阈值的数据包以未压缩格式发送)
+func writeLoginPacket(w io.Writer, body []byte, threshold int) error {
+	var packet, payload bytes.Buffer
+	if threshold >= 0 {
+		writeVarInt(&payload, 0)
+	}
+	payload.Write(body)
+	writeVarInt(&packet, payload.Len())
+	packet.Write(payload.Bytes())
+	_, err := w.Write(packet.Bytes())
+	return err
+}
+
+// 读取带 VarInt 长度前缀的字符串
+func readString(buf *bytes.Buffer) (string, error) {
+	length, err := readVarInt(buf)
+	if err != nil {
+		return "", err
+	}
+	if length < 0 || length > buf.Len() {
+		return "", fmt.Errorf("字符串长度无效: %d", length)
+	}
+	return string(buf.Next(length)), nil
+}
+
+// 将断开连接的原因 (JSON 文本组件) 渲染为可显示的文本
+func renderDisconnect(reason string) string {
+	var desc interface{}
+	if err := json.Unmarshal([]byte(reason), &desc); err != nil {
+		return reason
+	}
+	if !useColor {
+		return toPlainText(desc)
+	}
+	return toANSI(desc, cliRenderOptions())
+}
+
+// 执行登录探测并输出结果
+func printLoginProbe(w io.Writer, host string, port uint16, opts queryOptions, protocol int, username string) error {
+	if protocol <= 0 {
+		protocol = defaultProtocol
+	}
+	probe, err := probeLogin(host, port, opts.Timeout, opts.HandshakeHost, protocol, username)
+	fmt.Fprintf(w, "\n登录探测 (用户名: %s, 协议: %d):\n", username, protocol)
+	if err != nil {
+		fmt.Fprintln(w, "    探测失败:", describeError(err))
+		return err
+	}
+	if probe.Disconnect != "" {
+		fmt.Fprintln(w, "    服务器断开连接:", renderDisconnect(probe.Disconnect))
+	} else {
+		fmt.Fprintln(w, "    "+probe.Outcome)
+	}
+	return nil
+}
@@ -0,0 +1,263 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+// 按字节编码 VarInt, 用于构造测试数据
+func varInt(v int) []byte {
+	var buf bytes.Buffer
+	writeVarInt(&buf, v)
+	return buf.Bytes()
+}
+
+func TestReadVarIntFragmented(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want int
+	}{
+		{"单字节", []byte{0x01}, 1},
+		{"两字节", []byte{0xdd, 0x05}, 733},
+		{"五字节", []byte{0xff, 0xff, 0xff, 0xff, 0x07}, 2147483647},
+		{"负数", []byte{0xff, 0xff, 0xff, 0xff, 0x0f}, -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 每次只返回一个字节, 模拟 VarInt 被拆分到多个 TCP 分段
+			got, err := readVarInt(iotest.OneByteReader(bytes.NewReader(tt.data)))
+			if err != nil {
+				t.Fatalf("readVarInt() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("readVarInt() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadVarIntErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want error
+	}{
+		{"空数据", nil, io.EOF},
+		{"中途断开", []byte{0x80, 0x80}, io.ErrUnexpectedEOF},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := readVarInt(iotest.OneByteReader(bytes.NewReader(tt.data)))
+			if !errors.Is(err, tt.want) {
+				t.Errorf("readVarInt() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+
+	if _, err := readVarInt(bytes.NewReader([]byte{0x80, 0x80, 0x80, 0x80, 0x80, 0x01})); err == nil {
+		t.Error("readVarInt() 超过 5 字节时应返回错误")
+	}
+}
+
+func TestReadPacketFragmented(t *testing.T) {
+	json := `{"description":"hi"}`
+	payload := append([]byte{0x00}, varInt(len(json))...)
+	payload = append(payload, json...)
+	raw := append(varInt(len(payload)), payload...)
+
+	id, buf, err := readPacket(iotest.OneByteReader(bytes.NewReader(raw)))
+	if err != nil {
+		t.Fatalf("readPacket() error = %v", err)
+	}
+	if id != 0x00 {
+		t.Errorf("readPacket() id = %d, want 0", id)
+	}
+	length, err := readVarInt(buf)
+	if err != nil || length != len(json) || buf.String() != json {
+		t.Errorf("readPacket() payload = %q (length %d, err %v), want %q", buf.String(), length, err, json)
+	}
+
+	// 数据包在负载中途断开
+	if _, _, err := readPacket(iotest.OneByteReader(bytes.NewReader(raw[:len(raw)-3]))); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("readPacket() 截断时 error = %v, want %v", err, io.ErrUnexpectedEOF)
+	}
+}
+
+// 构造数据包: 长度 + 包 ID + 负载
+func packet(id int, payload []byte) []byte {
+	data := append(varInt(id), payload...)
+	return append(varInt(len(data)), data...)
+}
+
+// 构造状态响应包
+func statusPacket(json string) []byte {
+	return packet(0x00, append(varInt(len(json)), json...))
+}
+
+// 模拟服务器: 读取握手包与状态请求后发送状态响应, 再由 afterStatus 处理 ping 阶段, 最后关闭连接
+func fakeStatusServer(t *testing.T, json string, afterStatus func(conn net.Conn)) net.Conn {
+	t.Helper()
+	client, server := net.Pipe()
+	go func() {
+		defer server.Close()
+		for i := 0; i < 2; i++ {
+			if _, _, err := readPacket(server); err != nil {
+				return
+			}
+		}
+		if _, err := server.Write(statusPacket(json)); err != nil {
+			return
+		}
+		afterStatus(server)
+	}()
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+func TestParseExchangeClosedAfterStatus(t *testing.T) {
+	const status = `{"version":{"name":"Paper 1.20.4","protocol":765},"players":{"max":20,"online":1},"description":"hi"}`
+	tests := []struct {
+		name        string
+		afterStatus func(conn net.Conn)
+		wantPingErr bool
+	}{
+		{"正常返回 pong", func(conn net.Conn) {
+			if _, pong, err := readPacket(conn); err == nil {
+				conn.Write(packet(0x01, pong.Bytes()))
+			}
+		}, false},
+		{"状态响应后断开", func(conn net.Conn) {
+			readPacket(conn)
+		}, true},
+		{"pong 不完整", func(conn net.Conn) {
+			if _, pong, err := readPacket(conn); err == nil {
+				conn.Write(append(varInt(9), append([]byte{0x01}, pong.Bytes()[:4]...)...))
+			}
+		}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn := fakeStatusServer(t, status, tt.afterStatus)
+			got, err := parseExchange(exchangeStatus(conn, "127.0.0.1", 25565))
+			if err != nil {
+				t.Fatalf("parseExchange() error = %v, 应保留已收到的状态", err)
+			}
+			if got.Version == nil || got.Version.Name != "Paper 1.20.4" || got.Players == nil || got.Players.Online != 1 {
+				t.Errorf("parseExchange() 状态 = %+v", got)
+			}
+			if (got.PingErr != nil) != tt.wantPingErr {
+				t.Errorf("parseExchange() PingErr = %v, wantPingErr %v", got.PingErr, tt.wantPingErr)
+			}
+			if (got.PingEcho != nil) == tt.wantPingErr {
+				t.Errorf("parseExchange() PingEcho = %v, 仅在收到 pong 时应设置", got.PingEcho)
+			}
+		})
+	}
+}
+
+func TestParseExchangeErrors(t *testing.T) {
+	// 状态响应本身不完整时不应返回状态
+	client, server := net.Pipe()
+	defer client.Close()
+	go func() {
+		defer server.Close()
+		readPacket(server)
+		readPacket(server)
+		server.Write(statusPacket(`{"description":"hi"}`)[:8])
+	}()
+	if got, err := parseExchange(exchangeStatus(client, "127.0.0.1", 25565)); err == nil {
+		t.Errorf("parseExchange() = %+v, 状态响应不完整时应返回错误", got)
+	}
+
+	// ping 因其他原因失败 (如 pong 内容错误) 时不保留状态
+	err := &pingError{errors.New("pong 时间戳与发送的不一致")}
+	if got, err := parseExchange(`{"description":"hi"}`, 0, nil, err); err == nil {
+		t.Errorf("parseExchange() = %+v, want error", got)
+	}
+}
+
+func TestStatusWithoutVersion(t *testing.T) {
+	tests := []struct {
+		name         string
+		json         string
+		wantName     string
+		wantProtocol int
+	}{
+		{"缺少 version", `{"players":{"max":10,"online":2},"description":"hi"}`, "", 0},
+		{"version 为 null", `{"version":null,"description":"hi"}`, "", 0},
+		{"包含 version", `{"version":{"name":"Paper 1.20.4","protocol":765},"description":"hi"}`, "Paper 1.20.4", 765},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, err := parseStatus(tt.json, 0)
+			if err != nil {
+				t.Fatalf("parseStatus() error = %v", err)
+			}
+			if got := status.versionName(); got != tt.wantName {
+				t.Errorf("versionName() = %q, want %q", got, tt.wantName)
+			}
+			if got := status.protocol(); got != tt.wantProtocol {
+				t.Errorf("protocol() = %d, want %d", got, tt.wantProtocol)
+			}
+
+			var out bytes.Buffer
+			if err := printStatus(&out, status, "127.0.0.1", displayOptions{}); err != nil {
+				t.Fatalf("printStatus() error = %v", err)
+			}
+			line := compactLine(queryResult{Target: Target{Address: "127.0.0.1"}, Status: status})
+			if tt.wantName == "" {
+				if !strings.Contains(out.String(), "未知版本") || !strings.Contains(line, "未知版本") {
+					t.Errorf("缺少版本信息时应显示未知版本:\n%s\n%s", out.String(), line)
+				}
+				if strings.Contains(out.String(), "协议: 0") {
+					t.Errorf("缺少版本信息时不应显示协议 0:\n%s", out.String())
+				}
+			}
+		})
+	}
+}
+
+func TestRunResolveNoRecords(t *testing.T) {
+	// 解析成功但没有任何地址时不应 panic
+	putDNSCache("host:empty.invalid", dnsCacheEntry{})
+	var out bytes.Buffer
+	if code := runResolve(&out, Target{Address: "empty.invalid:25565"}, queryOptions{}); code != 1 {
+		t.Errorf("runResolve() = %d, want 1", code)
+	}
+	if !strings.Contains(out.String(), "未找到 A/AAAA 记录") {
+		t.Errorf("runResolve() 输出 = %q", out.String())
+	}
+}
+
+func TestParseAddressEmptyPort(t *testing.T) {
+	putDNSCache("srv:srv.invalid", dnsCacheEntry{srv: []*net.SRV{{Target: "real.invalid.", Port: 25570}}})
+	putDNSCache("srv:nosrv.invalid", dnsCacheEntry{err: errors.New("no such host")})
+	tests := []struct {
+		addr     string
+		portFlag int
+		wantHost string
+		wantPort uint16
+	}{
+		{"srv.invalid:", 0, "real.invalid", 25570},
+		{"nosrv.invalid:", 0, "nosrv.invalid", defaultPort},
+		{"srv.invalid:", 25580, "srv.invalid", 25580},
+		{"srv.invalid:25590", 0, "srv.invalid", 25590},
+	}
+	for _, tt := range tests {
+		t.Run(tt.addr, func(t *testing.T) {
+			host, port, err := parseAddress(tt.addr, tt.portFlag)
+			if err != nil {
+				t.Fatalf("parseAddress() error = %v", err)
+			}
+			if host != tt.wantHost || port != tt.wantPort {
+				t.Errorf("parseAddress(%q, %d) = %s:%d, want %s:%d", tt.addr, tt.portFlag, host, port, tt.wantHost, tt.wantPort)
+			}
+		})
+	}
+}
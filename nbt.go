@@ -0,0 +1,223 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+)
+
+// NBT 标签类型
+const (
+	tagEnd byte = iota
+	tagByte
+	tagShort
+	tagInt
+	tagLong
+	tagFloat
+	tagDouble
+	tagByteArray
+	tagString
+	tagList
+	tagCompound
+	tagIntArray
+	tagLongArray
+)
+
+const (
+	maxNBTDepth = 512      // 嵌套层数上限, 防止恶意文件导致栈溢出
+	maxNBTSize  = 16 << 20 // 解压后数据大小上限, 防止 gzip 炸弹
+)
+
+// NBT 读取器, 复合标签解析为 map[string]interface{}, 列表解析为 []interface{}
+// 数据已全部读入内存, 分配数组与字符串前先检查剩余数据是否足够, 防止伪造的长度导致分配大量内存
+type nbtReader struct {
+	r *bytes.Reader
+}
+
+func (n *nbtReader) read(v interface{}) error {
+	return binary.Read(n.r, binary.BigEndian, v)
+}
+
+func (n *nbtReader) readString() (string, error) {
+	var length uint16
+	if err := n.read(&length); err != nil {
+		return "", err
+	}
+	if int(length) > n.r.Len() {
+		return "", io.ErrUnexpectedEOF
+	}
+	buf := make([]byte, length)
+	if _, err := io.ReadFull(n.r, buf); err != nil {
+		return "", err
+	}
+	return string(buf), nil
+}
+
+// 读取数组长度并检查是否合法, elemSize 为每个元素至少占用的字节数
+func (n *nbtReader) readLength(elemSize int) (int, error) {
+	var length int32
+	if err := n.read(&length); err != nil {
+		return 0, err
+	}
+	if length < 0 {
+		return 0, fmt.Errorf("NBT 长度无效: %d", length)
+	}
+	if int64(length)*int64(elemSize) > int64(n.r.Len()) {
+		return 0, fmt.Errorf("NBT 长度 %d 超出剩余数据", length)
+	}
+	return int(length), nil
+}
+
+// 读取指定类型标签的负载
+func (n *nbtReader) readPayload(tag byte, depth int) (interface{}, error) {
+	if depth > maxNBTDepth {
+		return nil, fmt.Errorf("NBT 嵌套层数过深")
+	}
+	switch tag {
+	case tagByte:
+		var v int8
+		return v, n.read(&v)
+	case tagShort:
+		var v int16
+		return v, n.read(&v)
+	case tagInt:
+		var v int32
+		return v, n.read(&v)
+	case tagLong:
+		var v int64
+		return v, n.read(&v)
+	case tagFloat:
+		var v float32
+		return v, n.read(&v)
+	case tagDouble:
+		var v float64
+		return v, n.read(&v)
+	case tagByteArray:
+		length, err := n.readLength(1)
+		if err != nil {
+			return nil, err
+		}
+		buf := make([]byte, length)
+		_, err = io.ReadFull(n.r, buf)
+		return buf, err
+	case tagString:
+		return n.readString()
+	case tagList:
+		var elemTag byte
+		if err := n.read(&elemTag); err != nil {
+			return nil, err
+		}
+		length, err := n.readLength(1) // 除 TAG_End (会在下面报错) 外每个元素至少占 1 字节
+		if err != nil {
+			return nil, err
+		}
+		list := make([]interface{}, 0, min(length, 1024))
+		for i := 0; i < length; i++ {
+			v, err := n.readPayload(elemTag, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, v)
+		}
+		return list, nil
+	case tagCompound:
+		compound := map[string]interface{}{}
+		for {
+			var childTag byte
+			if err := n.read(&childTag); err != nil {
+				return nil, err
+			}
+			if childTag == tagEnd {
+				return compound, nil
+			}
+			name, err := n.readString()
+			if err != nil {
+				return nil, err
+			}
+			v, err := n.readPayload(childTag, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			compound[name] = v
+		}
+	case tagIntArray, tagLongArray:
+		size := 4
+		if tag == tagLongArray {
+			size = 8
+		}
+		length, err := n.readLength(size)
+		if err != nil {
+			return nil, err
+		}
+		buf := make([]byte, length*size)
+		_, err = io.ReadFull(n.r, buf)
+		return buf, err
+	default:
+		return nil, fmt.Errorf("未知的 NBT 标签类型: %d", tag)
+	}
+}
+
+// 解析 NBT 数据 (支持 gzip 压缩与未压缩格式), 返回根复合标签
+func parseNBT(data []byte) (map[string]interface{}, error) {
+	if len(data) >= 2 && data[0] == 0x1f && data[1] == 0x8b {
+		gz, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		defer gz.Close()
+		if data, err = io.ReadAll(io.LimitReader(gz, maxNBTSize+1)); err != nil {
+			return nil, err
+		}
+	}
+	if len(data) > maxNBTSize {
+		return nil, fmt.Errorf("NBT 数据过大 (超过 %d MB)", maxNBTSize>>20)
+	}
+	n := &nbtReader{r: bytes.NewReader(data)}
+
+	var tag byte
+	if err := n.read(&tag); err != nil {
+		return nil, err
+	}
+	if tag != tagCompound {
+		return nil, fmt.Errorf("根标签不是复合标签")
+	}
+	if _, err := n.readString(); err != nil {
+		return nil, err
+	}
+	root, err := n.readPayload(tagCompound, 0)
+	if err != nil {
+		return nil, err
+	}
+	return root.(map[string]interface{}), nil
+}
+
+// 从 Minecraft 的 servers.dat 文件导入已保存的服务器列表
+func importServersDat(path string) ([]Target, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	root, err := parseNBT(data)
+	if err != nil {
+		return nil, fmt.Errorf("servers.dat 解析失败: %w", err)
+	}
+
+	servers, _ := root["servers"].([]interface{})
+	var targets []Target
+	for _, entry := range servers {
+		server, ok := entry.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		ip, _ := server["ip"].(string)
+		if ip == "" {
+			continue
+		}
+		name, _ := server["name"].(string)
+		targets = append(targets, Target{Name: name, Address: ip})
+	}
+	return targets, nil
+}
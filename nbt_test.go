@@ -0,0 +1,61 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"testing"
+)
+
+// 构造根复合标签, 其中只有一个名为 "a" 的子标签, 负载由 payload 给出
+func nbtRoot(tag byte, payload []byte) []byte {
+	data := []byte{tagCompound, 0, 0, tag, 0, 1, 'a'}
+	data = append(data, payload...)
+	return append(data, tagEnd)
+}
+
+func nbtLength(n uint32) []byte {
+	return binary.BigEndian.AppendUint32(nil, n)
+}
+
+func TestParseNBTLengthLimit(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"字节数组长度过大", nbtRoot(tagByteArray, nbtLength(0x7fffffff))},
+		{"整数数组长度过大", nbtRoot(tagIntArray, nbtLength(0x1fffffff))},
+		{"长整数数组长度过大", nbtRoot(tagLongArray, nbtLength(0x0fffffff))},
+		{"列表长度过大", nbtRoot(tagList, append([]byte{tagByte}, nbtLength(0x7fffffff)...))},
+		{"字符串长度超出数据", nbtRoot(tagString, []byte{0xff, 0xff, 'x'})},
+		{"负数长度", nbtRoot(tagByteArray, nbtLength(0xffffffff))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := parseNBT(tt.data); err == nil {
+				t.Error("parseNBT() 声明的长度超出数据时应返回错误")
+			}
+		})
+	}
+
+	// 长度与数据一致时正常解析
+	root, err := parseNBT(nbtRoot(tagByteArray, append(nbtLength(2), 1, 2)))
+	if err != nil {
+		t.Fatalf("parseNBT() error = %v", err)
+	}
+	if got, ok := root["a"].([]byte); !ok || !bytes.Equal(got, []byte{1, 2}) {
+		t.Errorf("parseNBT() a = %v, want [1 2]", root["a"])
+	}
+}
+
+func TestParseNBTGzipLimit(t *testing.T) {
+	// 解压后超过大小上限的数据应被拒绝
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	gz.Write(nbtRoot(tagByteArray, nbtLength(maxNBTSize)))
+	gz.Write(make([]byte, maxNBTSize))
+	gz.Close()
+	if _, err := parseNBT(buf.Bytes()); err == nil {
+		t.Error("parseNBT() 解压后数据过大时应返回错误")
+	}
+}
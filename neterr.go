@@ -0,0 +1,78 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"syscall"
+)
+
+// 连接错误分类代码
+const (
+	errCodeDNS     = "DNS_FAILURE"
+	errCodeRefused = "CONNECTION_REFUSED"
+	errCodeReset   = "CONNECTION_RESET"
+	errCodeClosed  = "CONNECTION_CLOSED"
+	errCodeNoRoute = "NO_ROUTE"
+	errCodeTimeout = "TIMEOUT"
+	errCodeOther   = "ERROR"
+
+	errCodeNotMinecraft = "NOT_MINECRAFT" // 目标端口上运行的是其他服务
+
+	errCodeAddress  = "INVALID_ADDRESS"  // 地址格式错误
+	errCodeResponse = "INVALID_RESPONSE" // 已收到响应, 但状态无法解析或不符合规范
+)
+
+// 各类错误的说明
+var errorHints = map[string]string{
+	errCodeDNS:     "域名解析失败, 请检查地址是否拼写正确",
+	errCodeRefused: "连接被拒绝, 请检查端口是否正确、服务器是否已启动",
+	errCodeReset:   "连接被重置, 服务器或中间的防火墙主动断开了连接",
+	errCodeClosed:  "服务器提前关闭了连接, 可能不是 Minecraft 服务器或拒绝了状态查询",
+	errCodeNoRoute: "无法到达服务器所在网络, 请检查网络连接或地址是否正确",
+	errCodeTimeout: "连接超时, 服务器无响应或被防火墙拦截 (可使用 -t 延长超时时间)",
+}
+
+// 判断错误类型, 返回错误分类代码
+func classifyError(err error) string {
+	var dnsErr *net.DNSError
+	var ne net.Error
+	var nmErr *notMinecraftError
+	switch {
+	case errors.As(err, &dnsErr):
+		return errCodeDNS
+	case errors.As(err, &nmErr):
+		return errCodeNotMinecraft
+	case isErrno(err, errnoRefused):
+		return errCodeRefused
+	case isErrno(err, errnoReset):
+		return errCodeReset
+	case isErrno(err, errnoNoRoute):
+		return errCodeNoRoute
+	case errors.As(err, &ne) && ne.Timeout():
+		return errCodeTimeout
+	case errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		return errCodeClosed
+	}
+	return errCodeOther
+}
+
+// 判断错误是否为指定的系统错误之一
+func isErrno(err error, errnos []syscall.Errno) bool {
+	for _, errno := range errnos {
+		if errors.Is(err, errno) {
+			return true
+		}
+	}
+	return false
+}
+
+// 生成带说明的错误信息, 无法分类时原样返回
+func describeError(err error) string {
+	hint, ok := errorHints[classifyError(err)]
+	if !ok {
+		return err.Error()
+	}
+	return fmt.Sprintf("%s\n    错误详情: %v", hint, err)
+}
@@ -0,0 +1,11 @@
+//go:build !windows
+
+package main
+
+import "syscall"
+
+var (
+	errnoRefused = []syscall.Errno{syscall.ECONNREFUSED}
+	errnoReset   = []syscall.Errno{syscall.ECONNRESET, syscall.ECONNABORTED, syscall.EPIPE}
+	errnoNoRoute = []syscall.Errno{syscall.EHOSTUNREACH, syscall.ENETUNREACH}
+)
@@ -0,0 +1,20 @@
+//go:build windows
+
+package main
+
+import "syscall"
+
+// Windows 下的 Winsock 错误码 (syscall 包中未全部定义)
+const (
+	wsaENETUNREACH  syscall.Errno = 10051
+	wsaECONNABORTED syscall.Errno = 10053
+	wsaECONNRESET   syscall.Errno = 10054
+	wsaECONNREFUSED syscall.Errno = 10061
+	wsaEHOSTUNREACH syscall.Errno = 10065
+)
+
+var (
+	errnoRefused = []syscall.Errno{wsaECONNREFUSED}
+	errnoReset   = []syscall.Errno{wsaECONNRESET, wsaECONNABORTED}
+	errnoNoRoute = []syscall.Errno{wsaEHOSTUNREACH, wsaENETUNREACH}
+)
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"io"
+	"os"
+)
+
+// 查询结果的输出目标 (--output 时为文件), 提示信息与进度指示始终输出到标准输出
+// runSingle、runBatchMode 等各模式的入口与 printStatus 等输出函数均接收 io.Writer 参数, 仅 main 中传入 output
+var output io.Writer = os.Stdout
+
+// 记录首个写入错误的 Writer, 避免每次输出都检查错误
+type errWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (e *errWriter) Write(p []byte) (int, error) {
+	if e.err != nil {
+		return 0, e.err
+	}
+	n, err := e.w.Write(p)
+	e.err = err
+	return n, err
+}
+
+// 创建 (或清空) 结果输出文件
+func openOutput(path string) (*os.File, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	output = &errWriter{w: f}
+	return f, nil
+}
+
+// 关闭结果输出文件, 返回写入或关闭过程中的错误
+func closeOutput(f *os.File) error {
+	if f == nil {
+		return nil
+	}
+	werr := output.(*errWriter).err
+	output = os.Stdout
+	if err := f.Close(); werr == nil {
+		werr = err
+	}
+	return werr
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"time"
+)
+
+const defaultSampleInterval = time.Second // 多次测量延迟时的默认间隔
+
+// 延迟测量选项 (--ping-only / --count / --count-only)
+type pingOptions struct {
+	Only     bool          // 仅测量延迟, 不获取 MOTD
+	Count    int           // 测量次数, 0 表示默认 (逐次输出时为 4, 否则为 1)
+	Interval time.Duration // 两次测量之间的间隔, 0 表示默认 (1s)
+	Live     bool          // 每次测量完成即输出一行结果
+
+	SampleTimeout time.Duration // 多次测量时每次测量的超时时间 (--count-timeout), 0 表示同 --timeout
+}
+
+// 多次测量延迟的统计
+type pingStats struct {
+	sent, received int
+	min, max, sum  time.Duration
+}
+
+func (s *pingStats) add(rtt time.Duration) {
+	if s.received == 0 || rtt < s.min {
+		s.min = rtt
+	}
+	if rtt > s.max {
+		s.max = rtt
+	}
+	s.sum += rtt
+	s.received++
+}
+
+// 丢包率 (百分比), 不是整数时保留一位小数, 如 3 次丢失 1 次为 33.3
+func (s *pingStats) lossPercent() string {
+	if s.sent == 0 {
+		return "0"
+	}
+	loss := float64(s.sent-s.received) * 100 / float64(s.sent)
+	return strconv.FormatFloat(math.Round(loss*10)/10, 'f', -1, 64)
+}
+
+func (s *pingStats) String() string {
+	lost := s.sent - s.received
+	text := fmt.Sprintf("统计: 已发送 = %d, 已接收 = %d, 丢失 = %d (%s%% 丢失)", s.sent, s.received, lost, s.lossPercent())
+	if s.received == 0 {
+		return text
+	}
+	avg := s.sum / time.Duration(s.received)
+	return text + fmt.Sprintf("\n往返延迟: 最短 = %dms, 最长 = %dms, 平均 = %dms", s.min.Milliseconds(), s.max.Milliseconds(), avg.Milliseconds())
+}
+
+// 测量服务器延迟并输出结果, 返回退出码 (全部测量失败时为 1)
+func runPing(w io.Writer, host, ip string, port uint16, opts queryOptions, display displayOptions, p pingOptions) int {
+	count := p.Count
+	if count <= 0 {
+		count = 1
+		if p.Live {
+			count = 4
+		}
+	}
+	interval := p.Interval
+	if interval <= 0 {
+		interval = defaultSampleInterval
+	}
+
+	// 单次测量: 仅输出延迟
+	if count == 1 && !p.Live {
+		var rtt time.Duration
+		var err error
+		withSpinner(display.Spinner, "正在连接...", func() {
+			rtt, err = pingServer(host, port, opts.Timeout, opts.HandshakeHost)
+		})
+		if err != nil {
+			fmt.Fprintln(w, "\n无法连接到服务器:", describeError(err))
+			return 1
+		}
+		fmt.Fprintf(w, "\nPing 延迟 (%s): %s\n", rttLabel(), colorizePing(rtt))
+		return 0
+	}
+
+	// 每次测量单独计时, 超时只计为一次丢失, 不影响后续测量
+	sampleTimeout := cmp.Or(p.SampleTimeout, opts.Timeout)
+	var stats pingStats
+	if p.Live {
+		fmt.Fprintln(w)
+	}
+	for i := 0; i < count; i++ {
+		if i > 0 {
+			time.Sleep(interval)
+		}
+		stats.sent++
+		var rtt time.Duration
+		var err error
+		withSpinner(display.Spinner && !p.Live, fmt.Sprintf("正在测量 (%d/%d)...", i+1, count), func() {
+			rtt, err = pingServer(host, port, sampleTimeout, opts.HandshakeHost)
+		})
+		if err != nil {
+			if p.Live {
+				if classifyError(err) == errCodeTimeout {
+					fmt.Fprintln(w, "请求超时。")
+				} else {
+					fmt.Fprintf(w, "请求失败: %v\n", err)
+				}
+			}
+			continue
+		}
+		stats.add(rtt)
+		if p.Live {
+			fmt.Fprintf(w, "来自 %s [%s:%d] 的回复: 时间=%s\n", host, ip, port, colorizePing(rtt))
+		}
+	}
+
+	fmt.Fprintln(w, "\n"+stats.String())
+	if stats.received == 0 {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+)
+
+// 内置的协议号与游戏版本对照表 (编译时已知的正式版), 可使用 --protocol-map 补充新版本
+//
+//go:embed protocols.json
+var embeddedProtocols []byte
+
+// 协议号对应的游戏版本
+var protocolVersions = mustParseProtocolMap(embeddedProtocols)
+
+// 解析协议对照表: 键为协议号, 值为版本名称, 如 {"765": "1.20.3 - 1.20.4"}
+func parseProtocolMap(data []byte) (map[int]string, error) {
+	var raw map[string]string
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	versions := make(map[int]string, len(raw))
+	for key, name := range raw {
+		protocol, err := strconv.Atoi(key)
+		if err != nil {
+			return nil, fmt.Errorf("无效的协议号: %q", key)
+		}
+		versions[protocol] = name
+	}
+	return versions, nil
+}
+
+func mustParseProtocolMap(data []byte) map[int]string {
+	versions, err := parseProtocolMap(data)
+	if err != nil {
+		panic(err)
+	}
+	return versions
+}
+
+// 从文件加载协议对照表, 覆盖内置对照表中的同一协议号
+func loadProtocolMap(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	versions, err := parseProtocolMap(data)
+	if err != nil {
+		return err
+	}
+	for protocol, name := range versions {
+		protocolVersions[protocol] = name
+	}
+	return nil
+}
+
+// 协议号的展示文本, 对照表中有记录时附加对应的游戏版本
+func describeProtocol(protocol int) string {
+	if name, ok := protocolVersions[protocol]; ok {
+		return fmt.Sprintf("%d (%s)", protocol, name)
+	}
+	return strconv.Itoa(protocol)
+}
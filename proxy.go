@@ -0,0 +1,254 @@
+package main
+
+import (
+	"bufio"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// 连接服务器时使用的代理 (--proxy), 为 nil 时直接连接
+var proxyURL *url.URL
+
+// 解析代理地址, 支持 http://、socks5:// 与 socks5h://, 用户名与密码可写在地址中
+// socks5:// 在本地解析目标主机名, socks5h:// 将主机名交由代理解析
+func parseProxyURL(raw string) (*url.URL, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("无效的代理地址: %w", err)
+	}
+	switch u.Scheme {
+	case "http", "socks5", "socks5h":
+	default:
+		return nil, fmt.Errorf("不支持的代理类型: %q (可用: http, socks5, socks5h)", u.Scheme)
+	}
+	if u.Hostname() == "" {
+		return nil, fmt.Errorf("代理地址缺少主机名: %s", raw)
+	}
+	return u, nil
+}
+
+// 代理服务器的地址 (未指定端口时 http 使用 80, socks5 使用 1080)
+func proxyAddress(u *url.URL) string {
+	port := u.Port()
+	if port == "" {
+		port = "1080"
+		if u.Scheme == "http" {
+			port = "80"
+		}
+	}
+	return net.JoinHostPort(u.Hostname(), port)
+}
+
+// 通过代理建立到目标地址的 TCP 隧道
+func dialProxy(u *url.URL, address string, timeout time.Duration) (net.Conn, error) {
+	conn, err := newDialer(timeout).Dial(dialFamily.network("tcp"), proxyAddress(u)) // -4 / -6 同样适用于连接代理
+	if err != nil {
+		return nil, fmt.Errorf("无法连接到代理服务器: %w", err)
+	}
+	if deadline := connDeadline(timeout); !deadline.IsZero() {
+		conn.SetDeadline(deadline)
+	}
+
+	var tunnel net.Conn
+	if u.Scheme == "http" {
+		tunnel, err = httpConnect(conn, u, address)
+	} else {
+		tunnel, err = socks5Connect(conn, u, address)
+	}
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	conn.SetDeadline(time.Time{})
+	return tunnel, nil
+}
+
+// 发送 HTTP CONNECT 请求建立隧道
+func httpConnect(conn net.Conn, u *url.URL, address string) (net.Conn, error) {
+	req := "CONNECT " + address + " HTTP/1.1\r\nHost: " + address + "\r\n"
+	if u.User != nil {
+		password, _ := u.User.Password()
+		auth := base64.StdEncoding.EncodeToString([]byte(u.User.Username() + ":" + password))
+		req += "Proxy-Authorization: Basic " + auth + "\r\n"
+	}
+	if _, err := io.WriteString(conn, req+"\r\n"); err != nil {
+		return nil, err
+	}
+
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, &http.Request{Method: http.MethodConnect})
+	if err != nil {
+		return nil, fmt.Errorf("HTTP 代理响应无效: %w", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HTTP 代理拒绝了连接: %s", resp.Status)
+	}
+	if br.Buffered() > 0 {
+		return &bufferedConn{Conn: conn, r: br}, nil
+	}
+	return conn, nil
+}
+
+// 读取时优先返回已缓冲数据的连接
+type bufferedConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+func (c *bufferedConn) Read(p []byte) (int, error) {
+	return c.r.Read(p)
+}
+
+// SOCKS5 代理返回的错误代码说明
+var socks5Errors = map[byte]string{
+	0x01: "代理服务器内部错误",
+	0x02: "代理规则不允许该连接",
+	0x03: "网络不可达",
+	0x04: "主机不可达",
+	0x05: "连接被拒绝",
+	0x06: "TTL 已过期",
+	0x07: "不支持的命令",
+	0x08: "不支持的地址类型",
+}
+
+// 在本地解析 socks5:// 代理的目标主机名 (与直接连接一样使用 --dns 指定的服务器与 -4 / -6 的地址族), 返回 IP:端口
+func resolveSocks5Target(address string) (string, error) {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil || net.ParseIP(host) != nil {
+		return address, err
+	}
+	ips, err := lookupHostCached(host)
+	if err != nil {
+		return "", err
+	}
+	if ips = dialFamily.filter(ips); len(ips) == 0 {
+		return "", fmt.Errorf("%s 没有符合地址族的 IP 地址", host)
+	}
+	return net.JoinHostPort(ips[0], port), nil
+}
+
+// 通过 SOCKS5 协议建立隧道
+// socks5:// 向代理发送本地解析得到的 IP 地址, socks5h:// 发送主机名由代理解析
+func socks5Connect(conn net.Conn, u *url.URL, address string) (net.Conn, error) {
+	if u.Scheme == "socks5" {
+		resolved, err := resolveSocks5Target(address)
+		if err != nil {
+			return nil, err
+		}
+		address = resolved
+	}
+	host, portStr, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+	port, _ := strconv.Atoi(portStr)
+	if len(host) > 255 {
+		return nil, errors.New("主机名过长, 无法通过 SOCKS5 代理连接")
+	}
+
+	// 协商认证方式: 0x00 无需认证, 0x02 用户名/密码
+	methods := []byte{0x00}
+	if u.User != nil {
+		methods = append(methods, 0x02)
+	}
+	if _, err := conn.Write(append([]byte{0x05, byte(len(methods))}, methods...)); err != nil {
+		return nil, err
+	}
+	reply := make([]byte, 2)
+	if _, err := io.ReadFull(conn, reply); err != nil {
+		return nil, err
+	}
+	if reply[0] != 0x05 {
+		return nil, errors.New("代理服务器不是 SOCKS5 代理")
+	}
+	switch reply[1] {
+	case 0x00:
+	case 0x02:
+		if u.User == nil {
+			return nil, errors.New("SOCKS5 代理要求用户名与密码")
+		}
+		if err := socks5Auth(conn, u.User); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, errors.New("SOCKS5 代理不支持所提供的认证方式")
+	}
+
+	// 发送 CONNECT 请求
+	req := []byte{0x05, 0x01, 0x00}
+	if ip := net.ParseIP(host); ip != nil && ip.To4() != nil {
+		req = append(append(req, 0x01), ip.To4()...)
+	} else if ip != nil {
+		req = append(append(req, 0x04), ip.To16()...)
+	} else {
+		req = append(append(req, 0x03, byte(len(host))), host...)
+	}
+	req = binary.BigEndian.AppendUint16(req, uint16(port))
+	if _, err := conn.Write(req); err != nil {
+		return nil, err
+	}
+
+	// 读取响应: VER REP RSV ATYP BND.ADDR BND.PORT
+	head := make([]byte, 4)
+	if _, err := io.ReadFull(conn, head); err != nil {
+		return nil, err
+	}
+	if head[1] != 0x00 {
+		if msg, ok := socks5Errors[head[1]]; ok {
+			return nil, fmt.Errorf("SOCKS5 代理连接失败: %s", msg)
+		}
+		return nil, fmt.Errorf("SOCKS5 代理连接失败: 错误代码 %d", head[1])
+	}
+	var addrLen int
+	switch head[3] {
+	case 0x01:
+		addrLen = net.IPv4len
+	case 0x04:
+		addrLen = net.IPv6len
+	case 0x03:
+		b := make([]byte, 1)
+		if _, err := io.ReadFull(conn, b); err != nil {
+			return nil, err
+		}
+		addrLen = int(b[0])
+	default:
+		return nil, fmt.Errorf("SOCKS5 代理返回了未知的地址类型: %d", head[3])
+	}
+	if _, err := io.ReadFull(conn, make([]byte, addrLen+2)); err != nil {
+		return nil, err
+	}
+	return conn, nil
+}
+
+// SOCKS5 用户名/密码认证 (RFC 1929)
+func socks5Auth(conn net.Conn, user *url.Userinfo) error {
+	name := user.Username()
+	password, _ := user.Password()
+	if len(name) > 255 || len(password) > 255 {
+		return errors.New("SOCKS5 用户名或密码过长")
+	}
+	req := []byte{0x01, byte(len(name))}
+	req = append(req, name...)
+	req = append(req, byte(len(password)))
+	req = append(req, password...)
+	if _, err := conn.Write(req); err != nil {
+		return err
+	}
+	reply := make([]byte, 2)
+	if _, err := io.ReadFull(conn, reply); err != nil {
+		return err
+	}
+	if reply[1] != 0x00 {
+		return errors.New("SOCKS5 代理认证失败, 请检查用户名与密码")
+	}
+	return nil
+}
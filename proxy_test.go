@@ -0,0 +1,111 @@
+package main
+
+import (
+	"encoding/binary"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"testing"
+)
+
+// 模拟 SOCKS5 代理: 完成无认证协商后读取 CONNECT 请求, 返回请求中的地址类型 (ATYP) 与目标地址
+func fakeSocks5Proxy(t *testing.T) (net.Conn, <-chan [2]string) {
+	t.Helper()
+	client, server := net.Pipe()
+	got := make(chan [2]string, 1)
+	go func() {
+		defer server.Close()
+		head := make([]byte, 2)
+		if _, err := io.ReadFull(server, head); err != nil {
+			return
+		}
+		io.ReadFull(server, make([]byte, head[1]))
+		server.Write([]byte{0x05, 0x00})
+
+		req := make([]byte, 4)
+		if _, err := io.ReadFull(server, req); err != nil {
+			return
+		}
+		var host string
+		switch req[3] {
+		case 0x01:
+			ip := make(net.IP, net.IPv4len)
+			io.ReadFull(server, ip)
+			host = ip.String()
+		case 0x04:
+			ip := make(net.IP, net.IPv6len)
+			io.ReadFull(server, ip)
+			host = ip.String()
+		case 0x03:
+			n := make([]byte, 1)
+			io.ReadFull(server, n)
+			name := make([]byte, n[0])
+			io.ReadFull(server, name)
+			host = string(name)
+		}
+		port := make([]byte, 2)
+		io.ReadFull(server, port)
+		got <- [2]string{strconv.Itoa(int(req[3])), net.JoinHostPort(host, strconv.Itoa(int(binary.BigEndian.Uint16(port))))}
+		server.Write([]byte{0x05, 0x00, 0x00, 0x01, 0, 0, 0, 0, 0, 0})
+	}()
+	t.Cleanup(func() { client.Close() })
+	return client, got
+}
+
+func TestSocks5ConnectAddressType(t *testing.T) {
+	putDNSCache("host:socks.invalid", dnsCacheEntry{hosts: []string{"192.0.2.10"}})
+	tests := []struct {
+		name     string
+		proxy    string
+		address  string
+		wantType string // 1: IPv4, 3: 域名, 4: IPv6
+		wantAddr string
+	}{
+		{"socks5 本地解析域名", "socks5://127.0.0.1:1080", "socks.invalid:25565", "1", "192.0.2.10:25565"},
+		{"socks5h 由代理解析域名", "socks5h://127.0.0.1:1080", "socks.invalid:25565", "3", "socks.invalid:25565"},
+		{"socks5 IPv6 地址", "socks5://127.0.0.1:1080", "[2001:db8::1]:25565", "4", "[2001:db8::1]:25565"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, err := url.Parse(tt.proxy)
+			if err != nil {
+				t.Fatal(err)
+			}
+			conn, got := fakeSocks5Proxy(t)
+			if _, err := socks5Connect(conn, u, tt.address); err != nil {
+				t.Fatalf("socks5Connect() error = %v", err)
+			}
+			if req := <-got; req != [2]string{tt.wantType, tt.wantAddr} {
+				t.Errorf("CONNECT 请求 = ATYP %s %s, want ATYP %s %s", req[0], req[1], tt.wantType, tt.wantAddr)
+			}
+		})
+	}
+}
+
+func TestSocks5TargetFamily(t *testing.T) {
+	putDNSCache("host:dual.invalid", dnsCacheEntry{hosts: []string{"2001:db8::2", "192.0.2.20"}})
+	saved := dialFamily
+	t.Cleanup(func() { dialFamily = saved })
+
+	tests := []struct {
+		family ipFamily
+		want   string
+	}{
+		{familyAny, "[2001:db8::2]:25565"},
+		{familyIPv4, "192.0.2.20:25565"},
+		{familyIPv6, "[2001:db8::2]:25565"},
+	}
+	for _, tt := range tests {
+		dialFamily = tt.family
+		if got, err := resolveSocks5Target("dual.invalid:25565"); err != nil || got != tt.want {
+			t.Errorf("resolveSocks5Target() 地址族 %v = %q, %v, want %q", tt.family, got, err, tt.want)
+		}
+	}
+
+	putDNSCache("host:v6only.invalid", dnsCacheEntry{hosts: []string{"2001:db8::3"}})
+	dialFamily = familyIPv4
+	if _, err := resolveSocks5Target("v6only.invalid:25565"); err == nil {
+		t.Error("resolveSocks5Target() 没有符合地址族的地址时应返回错误")
+	}
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+)
+
+// 代理端常见的软件名称 (按匹配优先级排列, 分支放在上游之前)
+var proxySoftware = []string{"Waterfall", "FlameCord", "Travertine", "BungeeCord", "Velocity"}
+
+// 版本范围, 如 1.8-1.20、1.8.x-1.20.x、1.8 ~ 1.21.4
+var versionRangePattern = regexp.MustCompile(`\d+\.\d+(?:\.(?:\d+|x))?\s*[-~]\s*\d+\.\d+`)
+
+// 根据版本名称推测服务器是否为代理端 (BungeeCord / Velocity 等)
+// 返回识别出的软件名称, 仅能判断为版本范围时返回 "多版本"; 这只是启发式判断, 可能误判
+func detectProxy(version string) (string, bool) {
+	name := strings.ToLower(stripLegacyCodes(version))
+	for _, software := range proxySoftware {
+		if strings.Contains(name, strings.ToLower(software)) {
+			return software, true
+		}
+	}
+	if versionRangePattern.MatchString(name) {
+		return "多版本", true
+	}
+	return "", false
+}
@@ -0,0 +1,503 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Minecraft 颜色名称映射到 ANSI 终端颜色代码 (16 色)
+var minecraftColorMap = map[string]string{
+	"black":        "\033[30m",
+	"dark_blue":    "\033[34m",
+	"dark_green":   "\033[32m",
+	"dark_aqua":    "\033[36m",
+	"dark_red":     "\033[31m",
+	"dark_purple":  "\033[35m",
+	"gold":         "\033[33m",
+	"gray":         "\033[37m",
+	"dark_gray":    "\033[90m",
+	"blue":         "\033[94m",
+	"green":        "\033[92m",
+	"aqua":         "\033[96m",
+	"red":          "\033[91m",
+	"light_purple": "\033[95m",
+	"yellow":       "\033[93m",
+	"white":        "\033[97m",
+}
+
+// Minecraft 颜色名称对应的游戏内 RGB 值 (用于将十六进制颜色降级为最接近的 16 色)
+var minecraftRGB = map[string][3]uint8{
+	"black":        {0x00, 0x00, 0x00},
+	"dark_blue":    {0x00, 0x00, 0xAA},
+	"dark_green":   {0x00, 0xAA, 0x00},
+	"dark_aqua":    {0x00, 0xAA, 0xAA},
+	"dark_red":     {0xAA, 0x00, 0x00},
+	"dark_purple":  {0xAA, 0x00, 0xAA},
+	"gold":         {0xFF, 0xAA, 0x00},
+	"gray":         {0xAA, 0xAA, 0xAA},
+	"dark_gray":    {0x55, 0x55, 0x55},
+	"blue":         {0x55, 0x55, 0xFF},
+	"green":        {0x55, 0xFF, 0x55},
+	"aqua":         {0x55, 0xFF, 0xFF},
+	"red":          {0xFF, 0x55, 0x55},
+	"light_purple": {0xFF, 0x55, 0xFF},
+	"yellow":       {0xFF, 0xFF, 0x55},
+	"white":        {0xFF, 0xFF, 0xFF},
+}
+
+// 传统样式颜色码 (§) 对应的颜色名称
+var legacyColorNames = map[rune]string{
+	'0': "black", '1': "dark_blue", '2': "dark_green", '3': "dark_aqua",
+	'4': "dark_red", '5': "dark_purple", '6': "gold", '7': "gray",
+	'8': "dark_gray", '9': "blue", 'a': "green", 'b': "aqua",
+	'c': "red", 'd': "light_purple", 'e': "yellow", 'f': "white",
+}
+
+// 传统颜色码的顺序 (§0 - §f)
+const legacyColorCodes = "0123456789abcdef"
+
+// 支持的 Minecraft 颜色名称 (按 §0 - §f 的顺序)
+func supportedColors() []string {
+	names := make([]string, 0, len(legacyColorCodes))
+	for _, c := range legacyColorCodes {
+		names = append(names, legacyColorNames[c])
+	}
+	return names
+}
+
+// 列出支持的颜色名称、颜色码与 RGB 值, 并用对应颜色显示色块
+func printColorList(w io.Writer) {
+	fmt.Fprintln(w, "支持的颜色:")
+	for i, name := range supportedColors() {
+		rgb := minecraftRGB[name]
+		fmt.Fprintf(w, "    §%c  %-13s #%02X%02X%02X  %s\n", legacyColorCodes[i], name, rgb[0], rgb[1], rgb[2], colorize("██████ Minecraft", name))
+	}
+	fmt.Fprintln(w, "\n也可以使用 #RRGGBB 格式的十六进制颜色")
+}
+
+// 颜色深度 (每个颜色的位数), 可使用 --color-depth 选择
+const (
+	colorDepthNone = 0  // 不输出颜色
+	colorDepth16   = 4  // 4 位 16 色, 十六进制颜色降级为最接近的 Minecraft 颜色
+	colorDepth256  = 8  // 8 位 256 色
+	colorDepthTrue = 24 // 24 位真彩色
+)
+
+// 控制将描述渲染为 ANSI 文本的方式
+type renderOptions struct {
+	ColorDepth int    // 颜色深度 (位数): colorDepthNone / colorDepth16 / colorDepth256 / colorDepthTrue
+	Formatting bool   // 是否渲染粗体、斜体、下划线与删除线
+	Background string // 背景色 (颜色名称或 #RRGGBB), 为空表示不设置
+	Obfuscate  string // 混淆文本的显示方式: mask (默认) / random / plain
+}
+
+// --color-depth 可选的值与对应的颜色深度
+var colorDepthNames = map[string]int{"16": colorDepth16, "256": colorDepth256, "truecolor": colorDepthTrue}
+
+// 解析 --color-depth 的值
+func parseColorDepth(s string) (int, error) {
+	depth, ok := colorDepthNames[strings.ToLower(s)]
+	if !ok {
+		return 0, fmt.Errorf("无效的颜色深度: %s (可选 16 / 256 / truecolor)", s)
+	}
+	return depth, nil
+}
+
+// 命令行使用的渲染选项
+func cliRenderOptions() renderOptions {
+	opts := renderOptions{ColorDepth: motdColorDepth, Formatting: !motdNoFormat, Background: motdBackground, Obfuscate: obfuscateMode}
+	if motdNoColor {
+		opts.ColorDepth = colorDepthNone
+	}
+	return opts
+}
+
+// 将任意格式的描述 (JSON 对象、字符串或数组) 渲染为带 ANSI 样式的文本, 无法解析时返回空字符串
+func toANSI(desc interface{}, opts renderOptions) string {
+	component, err := toChatComponent(desc)
+	if err != nil {
+		return ""
+	}
+	return renderANSI(component, opts)
+}
+
+const ansiBold, ansiItalic, ansiUnderline, ansiStrike = "\033[1m", "\033[3m", "\033[4m", "\033[9m"
+
+// 将十六进制颜色值转换为 ANSI 真彩色代码
+func hexToANSI(hex string) string {
+	rgb, ok := parseHexColor(hex)
+	if !ok {
+		return ""
+	}
+	return fmt.Sprintf("\033[38;2;%d;%d;%dm", rgb[0], rgb[1], rgb[2])
+}
+
+// 解析 #rrggbb 格式的颜色
+func parseHexColor(hex string) ([3]uint8, bool) {
+	if len(hex) != 7 || hex[0] != '#' {
+		return [3]uint8{}, false
+	}
+	v, err := strconv.ParseUint(hex[1:], 16, 32)
+	if err != nil {
+		return [3]uint8{}, false
+	}
+	return [3]uint8{uint8(v >> 16), uint8(v >> 8), uint8(v)}, true
+}
+
+// 获取颜色名称或十六进制颜色的 ANSI 码 (真彩色)
+func getColorANSI(color string) string {
+	return colorANSI(color, colorDepthTrue)
+}
+
+// 颜色名称使用游戏中的精确 RGB 值 (--accurate-colors), 而不是随终端配色变化的 16 色
+var accurateColors bool
+
+// 按颜色深度获取颜色名称或十六进制颜色的 ANSI 码
+func colorANSI(color string, depth int) string {
+	if code, ok := themeANSI(color, depth); ok {
+		return code
+	}
+	if code, ok := minecraftColorMap[color]; ok {
+		switch {
+		case depth == colorDepthNone:
+			return ""
+		case accurateColors && depth == colorDepthTrue:
+			rgb := minecraftRGB[color]
+			return hexToANSI(fmt.Sprintf("#%02x%02x%02x", rgb[0], rgb[1], rgb[2]))
+		case accurateColors && depth == colorDepth256:
+			return fmt.Sprintf("\033[38;5;%dm", rgbTo256(minecraftRGB[color]))
+		}
+		return code
+	}
+	rgb, ok := parseHexColor(color)
+	if !ok {
+		return ""
+	}
+	switch depth {
+	case colorDepthTrue:
+		return hexToANSI(color)
+	case colorDepth256:
+		return fmt.Sprintf("\033[38;5;%dm", rgbTo256(rgb))
+	case colorDepth16:
+		return minecraftColorMap[nearestMinecraftColor(rgb)]
+	}
+	return ""
+}
+
+// 查找与 RGB 值最接近的 Minecraft 颜色名称
+func nearestMinecraftColor(rgb [3]uint8) string {
+	best, bestDist := "white", -1
+	for name, c := range minecraftRGB {
+		d := colorDistance(rgb, c)
+		if bestDist < 0 || d < bestDist || (d == bestDist && name < best) {
+			best, bestDist = name, d
+		}
+	}
+	return best
+}
+
+func colorDistance(a, b [3]uint8) int {
+	dist := 0
+	for i := range a {
+		d := int(a[i]) - int(b[i])
+		dist += d * d
+	}
+	return dist
+}
+
+// 将 RGB 值转换为最接近的 xterm 256 色索引 (6x6x6 色块或灰阶)
+func rgbTo256(rgb [3]uint8) int {
+	levels := [6]int{0, 95, 135, 175, 215, 255}
+	var cube [3]uint8
+	index := 16
+	for i, v := range rgb {
+		best := 0
+		for j, l := range levels {
+			if abs(int(v)-l) < abs(int(v)-levels[best]) {
+				best = j
+			}
+		}
+		cube[i] = uint8(levels[best])
+		index += best * []int{36, 6, 1}[i]
+	}
+
+	avg := (int(rgb[0]) + int(rgb[1]) + int(rgb[2])) / 3
+	grayIndex := min(max((avg-8+5)/10, 0), 23)
+	gray := uint8(8 + grayIndex*10)
+	if colorDistance(rgb, [3]uint8{gray, gray, gray}) < colorDistance(rgb, cube) {
+		return 232 + grayIndex
+	}
+	return index
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// 将前景色 ANSI 码转换为对应的背景色 ANSI 码
+func backgroundANSI(code string) string {
+	switch {
+	case strings.HasPrefix(code, "\033[38;"):
+		return "\033[48;" + code[len("\033[38;"):]
+	case len(code) == 5 && code[2] == '3':
+		return "\033[4" + code[3:]
+	case len(code) == 5 && code[2] == '9':
+		return "\033[10" + code[3:]
+	}
+	return ""
+}
+
+// 输出前将 MOTD 文本规范化为 NFC (可通过 --no-normalize 关闭), 使分解形式的重音字符等正常显示
+var normalizeNFC = true
+
+// 按设置规范化文本 (ANSI 转义序列均为 ASCII, 不受影响)
+func normalizeText(s string) string {
+	if !normalizeNFC {
+		return s
+	}
+	return norm.NFC.String(s)
+}
+
+var motdBackground string // MOTD 背景色 (--bg), 为空表示不设置背景
+
+var motdColorDepth = colorDepthTrue // MOTD 的颜色深度 (--color-depth), 终端不支持真彩色时可降级为 256 或 16 色
+
+var (
+	motdNoFormat bool // 不渲染粗体、斜体等格式, 仅保留颜色 (--no-format)
+	motdNoColor  bool // 不渲染颜色, 仅保留格式 (--no-color-only)
+)
+
+// 混淆文本 (§k) 的显示方式: mask 显示为固定字符, random 显示为随机字符, plain 显示原文
+var obfuscateMode = "mask"
+
+const obfuscateGlyphs = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789!@#$%^&*?"
+
+// 按混淆方式替换单个字符 (空白字符保持不变)
+func obfuscateRune(r rune, mode string) rune {
+	if unicode.IsSpace(r) {
+		return r
+	}
+	switch mode {
+	case "", "mask":
+		return '▒'
+	case "random":
+		return rune(obfuscateGlyphs[rand.IntN(len(obfuscateGlyphs))])
+	}
+	return r
+}
+
+// 解析 BungeeCord 格式的十六进制颜色 (§x§r§r§g§g§b§b), 返回 #rrggbb
+func parseLegacyHex(runes []rune) (string, bool) {
+	if len(runes) < 14 {
+		return "", false
+	}
+	hex := []rune{'#'}
+	for j := 2; j < 14; j += 2 {
+		if runes[j] != '§' || !strings.ContainsRune("0123456789abcdefABCDEF", runes[j+1]) {
+			return "", false
+		}
+		hex = append(hex, unicode.ToLower(runes[j+1]))
+	}
+	return string(hex), true
+}
+
+// 文本样式 (颜色与格式)
+type textStyle struct {
+	color                                               string
+	bold, italic, underlined, strikethrough, obfuscated bool
+}
+
+// 组件的样式: 未设置的字段继承父组件
+func (s textStyle) inherit(c ChatComponent) textStyle {
+	if c.Color != "" {
+		s.color = c.Color
+	}
+	set := func(dst *bool, v *bool) {
+		if v != nil {
+			*dst = *v
+		}
+	}
+	set(&s.bold, c.Bold)
+	set(&s.italic, c.Italic)
+	set(&s.underlined, c.Underlined)
+	set(&s.strikethrough, c.Strikethrough)
+	set(&s.obfuscated, c.Obfuscated)
+	return s
+}
+
+// 应用传统样式代码, 返回代码是否有效
+// 颜色码会清除之前的格式, §r 恢复为组件本身的样式
+func (s *textStyle) applyLegacy(c rune, base textStyle) bool {
+	if name, ok := legacyColorNames[c]; ok {
+		*s = textStyle{color: name}
+		return true
+	}
+	switch c {
+	case 'k':
+		s.obfuscated = true
+	case 'l':
+		s.bold = true
+	case 'm':
+		s.strikethrough = true
+	case 'n':
+		s.underlined = true
+	case 'o':
+		s.italic = true
+	case 'r':
+		*s = base
+	default:
+		return false
+	}
+	return true
+}
+
+// 按渲染选项输出 ANSI 文本
+// 只在样式变化时输出差异部分, 仅在需要关闭某种格式或清除颜色时才重置,
+// 避免逐字着色的渐变 MOTD 在字符之间插入重置代码
+type ansiRenderer struct {
+	opts    renderOptions
+	b       strings.Builder
+	cur     textStyle // 当前已输出的样式
+	started bool      // 是否已输出过样式
+}
+
+// 是否会输出任何 ANSI 代码
+func (r *ansiRenderer) styled() bool {
+	return r.opts.ColorDepth != colorDepthNone || r.opts.Formatting
+}
+
+// 样式中各格式对应的 ANSI 码 (未启用格式渲染时为空)
+func (r *ansiRenderer) formats(s textStyle) []string {
+	if !r.opts.Formatting {
+		return nil
+	}
+	var codes []string
+	for _, f := range []struct {
+		on   bool
+		code string
+	}{{s.bold, ansiBold}, {s.italic, ansiItalic}, {s.underlined, ansiUnderline}, {s.strikethrough, ansiStrike}} {
+		if f.on {
+			codes = append(codes, f.code)
+		}
+	}
+	return codes
+}
+
+// 切换到指定样式
+func (r *ansiRenderer) setStyle(s textStyle) {
+	if !r.styled() {
+		return
+	}
+	color := colorANSI(s.color, r.opts.ColorDepth)
+	prevColor := colorANSI(r.cur.color, r.opts.ColorDepth)
+	next, prev := r.formats(s), r.formats(r.cur)
+
+	// 需要清除颜色或关闭格式时, 重置后重新设置背景色、前景色与格式 (首次设置样式时无需重置)
+	if !r.started || (color == "" && prevColor != "") || !containsAll(next, prev) {
+		if r.started {
+			r.b.WriteString(ansiReset)
+		}
+		if r.opts.Background != "" {
+			r.b.WriteString(backgroundANSI(colorANSI(r.opts.Background, r.opts.ColorDepth)))
+		}
+		r.b.WriteString(color)
+		r.b.WriteString(strings.Join(next, ""))
+	} else {
+		if color != prevColor {
+			r.b.WriteString(color)
+		}
+		for _, code := range next {
+			if !slices.Contains(prev, code) {
+				r.b.WriteString(code)
+			}
+		}
+	}
+	r.cur, r.started = s, true
+}
+
+// 判断 a 是否包含 b 中的全部元素
+func containsAll(a, b []string) bool {
+	for _, v := range b {
+		if !slices.Contains(a, v) {
+			return false
+		}
+	}
+	return true
+}
+
+// 渲染带 § 代码的文本, base 为所属组件的样式
+func (r *ansiRenderer) writeLegacy(s string, base textStyle) {
+	if s == "" {
+		return // 空文本 (如只有 extra 的组件) 不输出样式, 避免多余的重置
+	}
+	style := base
+	r.setStyle(style)
+	runes := []rune(s)
+	for i := 0; i < len(runes); {
+		if runes[i] == '§' && i+1 < len(runes) {
+			c := unicode.ToLower(runes[i+1])
+			if c == 'x' {
+				if hex, ok := parseLegacyHex(runes[i:]); ok {
+					style = textStyle{color: hex}
+					r.setStyle(style)
+					i += 14
+					continue
+				}
+			}
+			if style.applyLegacy(c, base) {
+				r.setStyle(style)
+				i += 2
+				continue
+			}
+		}
+		if runes[i] == '\n' && r.started {
+			// 换行前重置样式, 避免背景色延伸到行尾, 在下一行输出文字前再重新设置样式
+			r.b.WriteString(ansiReset + "\n")
+			r.started = false
+			i++
+			continue
+		}
+		if !r.started {
+			r.setStyle(style)
+		}
+		if style.obfuscated {
+			r.b.WriteRune(obfuscateRune(runes[i], r.opts.Obfuscate))
+		} else {
+			r.b.WriteRune(runes[i])
+		}
+		i++
+	}
+}
+
+// 递归渲染聊天组件
+func (r *ansiRenderer) writeComponent(c ChatComponent, parent textStyle) {
+	style := parent.inherit(c)
+	r.writeLegacy(c.Text, style)
+	for _, child := range c.Extra {
+		if child.TextComponent != nil {
+			r.writeComponent(*child.TextComponent, style)
+		} else {
+			r.writeLegacy(child.RawString, style)
+		}
+	}
+}
+
+// 将聊天组件渲染为 ANSI 文本
+func renderANSI(component ChatComponent, opts renderOptions) string {
+	r := &ansiRenderer{opts: opts}
+	r.writeComponent(component, textStyle{})
+	if r.styled() {
+		r.b.WriteString(ansiReset)
+	}
+	return normalizeText(r.b.String())
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// 解析 JSON 格式的描述, 用于构造测试数据
+func mustComponent(t *testing.T, raw string) ChatComponent {
+	t.Helper()
+	var desc interface{}
+	if err := json.Unmarshal([]byte(raw), &desc); err != nil {
+		t.Fatalf("无效的测试 JSON: %v", err)
+	}
+	component, err := toChatComponent(desc)
+	if err != nil {
+		t.Fatalf("toChatComponent() error = %v", err)
+	}
+	return component
+}
+
+func TestRenderGradient(t *testing.T) {
+	tests := []struct {
+		name  string
+		desc  string
+		depth int
+		want  string
+	}{
+		{
+			"逐字十六进制颜色",
+			`{"text":"","extra":[{"text":"A","color":"#ff0000"},{"text":"B","color":"#00ff00"},{"text":"C","color":"#0000ff"}]}`,
+			colorDepthTrue,
+			"\033[38;2;255;0;0mA\033[38;2;0;255;0mB\033[38;2;0;0;255mC" + ansiReset,
+		},
+		{
+			"渐变保留粗体",
+			`{"text":"","bold":true,"extra":[{"text":"A","color":"#ff0000"},{"text":"B","color":"#ff8000"}]}`,
+			colorDepthTrue,
+			"\033[38;2;255;0;0m" + ansiBold + "A\033[38;2;255;128;0mB" + ansiReset,
+		},
+		{
+			"相同颜色不重复输出",
+			`{"extra":[{"text":"A","color":"#123456"},{"text":"B","color":"#123456"}]}`,
+			colorDepthTrue,
+			"\033[38;2;18;52;86mAB" + ansiReset,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := renderANSI(mustComponent(t, tt.desc), renderOptions{ColorDepth: tt.depth, Formatting: true})
+			if got != tt.want {
+				t.Errorf("renderANSI() = %q, want %q", got, tt.want)
+			}
+			// 字符之间不应插入重置代码, 否则渐变显示会断开
+			if strings.Count(got, ansiReset) != 1 {
+				t.Errorf("renderANSI() = %q, 重置代码只应出现在末尾", got)
+			}
+		})
+	}
+}
+
+func TestRenderNewlines(t *testing.T) {
+	tests := []struct {
+		name   string
+		desc   string
+		styled string // 16 色渲染结果
+		plain  string // 纯文本
+	}{
+		{
+			"传统样式代码",
+			`"§aLine1\n§bLine2"`,
+			"\033[92mLine1" + ansiReset + "\n\033[96mLine2" + ansiReset,
+			"Line1\nLine2",
+		},
+		{
+			"组件之间换行",
+			`{"text":"One\n","color":"red","extra":[{"text":"Two","color":"blue"}]}`,
+			"\033[91mOne" + ansiReset + "\n\033[94mTwo" + ansiReset,
+			"One\nTwo",
+		},
+		{
+			"§ 位于行尾",
+			`"a§\nb"`,
+			"a§" + ansiReset + "\nb" + ansiReset,
+			"a§\nb",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := mustComponent(t, tt.desc)
+			if got := renderANSI(c, renderOptions{ColorDepth: colorDepth16, Formatting: true}); got != tt.styled {
+				t.Errorf("renderANSI() = %q, want %q", got, tt.styled)
+			}
+			if got := renderANSI(c, renderOptions{}); got != tt.plain {
+				t.Errorf("renderANSI() 无样式 = %q, want %q", got, tt.plain)
+			}
+			if got := plainText(c); got != tt.plain {
+				t.Errorf("plainText() = %q, want %q", got, tt.plain)
+			}
+		})
+	}
+
+	// § 后紧跟换行时不应吞掉换行
+	if got := stripLegacyCodes("§cRed§\n§lBold"); got != "Red§\nBold" {
+		t.Errorf("stripLegacyCodes() = %q, want %q", got, "Red§\nBold")
+	}
+}
+
+func TestRenderExtraOnly(t *testing.T) {
+	tests := []struct {
+		name string
+		desc string
+		want string
+	}{
+		{
+			"首个子组件的颜色",
+			`{"extra":[{"text":"Hi","color":"gold"}," there"]}`,
+			"\033[33mHi" + ansiReset + " there" + ansiReset,
+		},
+		{
+			"首个子组件的格式",
+			`{"extra":[{"text":"Hi ","color":"gold","bold":true},{"text":"x"}]}`,
+			"\033[33m" + ansiBold + "Hi " + ansiReset + "x" + ansiReset,
+		},
+		{
+			"嵌套的空组件",
+			`{"extra":[{"extra":[{"text":"A","color":"red"}]}]}`,
+			"\033[91mA" + ansiReset,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := renderANSI(mustComponent(t, tt.desc), renderOptions{ColorDepth: colorDepth16, Formatting: true})
+			if got != tt.want {
+				t.Errorf("renderANSI() = %q, want %q", got, tt.want)
+			}
+			// 没有文本的外层组件不应在开头输出重置代码
+			if strings.HasPrefix(got, ansiReset) {
+				t.Errorf("renderANSI() = %q, 开头不应是重置代码", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 重复查询选项 (--repeat)
+type repeatOptions struct {
+	Count    int           // 完整查询的次数, 不大于 1 时只查询一次
+	Interval time.Duration // 两次查询之间的间隔, 0 表示默认 (1s)
+	Collect  bool          // 只收集并显示不同的 MOTD (--collect-motds)
+}
+
+// 一次查询中用于比较的字段
+var repeatFields = []struct {
+	name  string
+	value func(*ServerStatus) string
+}{
+	{"MOTD", func(s *ServerStatus) string { return strings.Join(strings.Fields(toPlainText(s.Description)), " ") }},
+	{"服务端", func(s *ServerStatus) string {
+		if s.Version == nil {
+			return "未知"
+		}
+		return stripLegacyCodes(s.Version.Name)
+	}},
+	{"协议", func(s *ServerStatus) string {
+		if s.Version == nil {
+			return "未知"
+		}
+		return strconv.Itoa(s.Version.Protocol)
+	}},
+	{"在线人数", func(s *ServerStatus) string {
+		if s.Players == nil {
+			return "N/A"
+		}
+		return strconv.Itoa(s.Players.Online)
+	}},
+	{"最大人数", func(s *ServerStatus) string {
+		if s.Players == nil {
+			return "N/A"
+		}
+		return strconv.Itoa(s.Players.Max)
+	}},
+	{"服务器图标", func(s *ServerStatus) string {
+		if s.Favicon == "" {
+			return "未设置"
+		}
+		sum := sha256.Sum256([]byte(s.Favicon))
+		return hex.EncodeToString(sum[:])[:12]
+	}},
+}
+
+// 重复执行完整的状态查询, 逐次输出单行摘要, 最后汇总各字段在多次查询中是否变化, 返回退出码 (全部失败时为 1)
+func runRepeat(w io.Writer, t Target, host, ip string, port uint16, opts queryOptions, display displayOptions, r repeatOptions) int {
+	if r.Collect {
+		return collectMOTDs(w, host, port, opts, display, r)
+	}
+	interval := r.Interval
+	if interval <= 0 {
+		interval = defaultSampleInterval
+	}
+
+	// 每个字段按首次出现的顺序记录不同的取值
+	values := make([][]string, len(repeatFields))
+	succeeded := 0
+	fmt.Fprintln(w)
+	for i := 0; i < r.Count; i++ {
+		if i > 0 {
+			time.Sleep(interval)
+		}
+		var status *ServerStatus
+		var err error
+		withSpinner(display.Spinner, fmt.Sprintf("正在查询 (%d/%d)...", i+1, r.Count), func() {
+			status, err = queryWithRetry(host, port, opts)
+		})
+		fmt.Fprintf(w, "[%d/%d] %s\n", i+1, r.Count, compactLine(queryResult{Target: t, Host: host, Port: port, IP: ip, Status: status, Err: err}))
+		if err != nil {
+			continue
+		}
+		succeeded++
+		for j, field := range repeatFields {
+			if v := field.value(status); !slices.Contains(values[j], v) {
+				values[j] = append(values[j], v)
+			}
+		}
+	}
+
+	fmt.Fprintf(w, "\n统计: 查询 %d 次, 成功 %d 次\n", r.Count, succeeded)
+	if succeeded == 0 {
+		return 1
+	}
+	var changed []string
+	for j, field := range repeatFields {
+		if len(values[j]) > 1 {
+			changed = append(changed, fmt.Sprintf("    %s: %d 种取值 (%s)", field.name, len(values[j]), strings.Join(values[j], " | ")))
+		}
+	}
+	if len(changed) == 0 {
+		fmt.Fprintln(w, "各次查询结果一致, 没有字段发生变化")
+		return 0
+	}
+	fmt.Fprintln(w, "以下字段在多次查询中发生了变化:")
+	for _, line := range changed {
+		fmt.Fprintln(w, line)
+	}
+	return 0
+}
+
+// 收集到的一种 MOTD
+type motdVariant struct {
+	description ChatComponent
+	count       int // 出现的次数
+}
+
+// 重复查询并收集服务器返回的不同 MOTD (许多服务器会轮换多条 MOTD), 按首次出现的顺序显示各个 MOTD 及出现次数
+// 按描述的原始 JSON 区分, 格式不同但文字相同的 MOTD 视为不同的 MOTD
+func collectMOTDs(w io.Writer, host string, port uint16, opts queryOptions, display displayOptions, r repeatOptions) int {
+	interval := r.Interval
+	if interval <= 0 {
+		interval = defaultSampleInterval
+	}
+
+	var variants []*motdVariant
+	index := map[string]*motdVariant{}
+	succeeded := 0
+	for i := 0; i < r.Count; i++ {
+		if i > 0 {
+			time.Sleep(interval)
+		}
+		var status *ServerStatus
+		var err error
+		withSpinner(display.Spinner, fmt.Sprintf("正在收集 MOTD (%d/%d)...", i+1, r.Count), func() {
+			status, err = queryWithRetry(host, port, opts)
+		})
+		if err != nil {
+			fmt.Fprintf(w, "第 %d 次查询失败: %v\n", i+1, err)
+			continue
+		}
+		description, err := toChatComponent(status.Description)
+		if err != nil {
+			continue
+		}
+		succeeded++
+		key, _ := json.Marshal(status.Description)
+		if v, ok := index[string(key)]; ok {
+			v.count++
+			continue
+		}
+		v := &motdVariant{description: description, count: 1}
+		index[string(key)] = v
+		variants = append(variants, v)
+	}
+
+	if succeeded == 0 {
+		fmt.Fprintf(w, "\n查询 %d 次, 全部失败\n", r.Count)
+		return 1
+	}
+	fmt.Fprintf(w, "\n查询 %d 次 (成功 %d 次), 共发现 %d 种 MOTD:\n", r.Count, succeeded, len(variants))
+	for i, v := range variants {
+		fmt.Fprintf(w, "\n#%d (出现 %d 次)\n", i+1, v.count)
+		if display.Plain {
+			fmt.Fprintln(w, plainText(v.description))
+		} else {
+			fmt.Fprintln(w, renderANSI(v.description, cliRenderOptions()))
+		}
+	}
+	return 0
+}
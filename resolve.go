@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// 仅解析地址 (--resolve-only): 显示 SRV 记录与 A/AAAA 记录, 不连接服务器, 返回退出码
+func runResolve(w io.Writer, t Target, opts queryOptions) int {
+	host, portStr, err := splitAddress(t.Address)
+	if err != nil {
+		fmt.Fprintln(w, err)
+		return 1
+	}
+	fmt.Fprintf(w, "%s:\n", t.Address)
+
+	target, port := host, fallbackPort
+	switch {
+	case portStr != "":
+		p, err := strconv.ParseUint(portStr, 10, 16)
+		if err != nil || p == 0 {
+			fmt.Fprintln(w, "    无效的端口:", portStr)
+			return 1
+		}
+		port = uint16(p)
+		fmt.Fprintln(w, "    SRV 记录: 地址中已包含端口, 不查询")
+	case opts.Port > 0:
+		port = uint16(opts.Port)
+		fmt.Fprintln(w, "    SRV 记录: 已通过 --port 指定端口, 不查询")
+	case net.ParseIP(host) != nil:
+		fmt.Fprintln(w, "    SRV 记录: 地址为 IP, 不查询")
+	default:
+		records, err := lookupSRVCached(host)
+		if err != nil || len(records) == 0 {
+			fmt.Fprintf(w, "    SRV 记录: 无 (_minecraft._tcp.%s)\n", host)
+		} else {
+			for i, srv := range records {
+				fmt.Fprintf(w, "    SRV 记录: %s (优先级 %d, 权重 %d)\n", net.JoinHostPort(strings.TrimSuffix(srv.Target, "."), strconv.Itoa(int(srv.Port))), srv.Priority, srv.Weight)
+				if i == 0 {
+					target, port = strings.TrimSuffix(srv.Target, "."), srv.Port
+				}
+			}
+		}
+	}
+	fmt.Fprintf(w, "    连接目标: %s\n", net.JoinHostPort(target, strconv.Itoa(int(port))))
+
+	ips, err := lookupHostCached(target)
+	if err != nil {
+		fmt.Fprintf(w, "    %s\n", describeError(err))
+		return 1
+	}
+	if len(ips) == 0 {
+		fmt.Fprintln(w, "    未找到 A/AAAA 记录")
+		return 1
+	}
+	if matched := dnsFamily.filter(ips); len(matched) < len(ips) {
+		fmt.Fprintf(w, "    (已隐藏 %d 个其他地址族的地址)\n", len(ips)-len(matched))
+		if ips = matched; len(ips) == 0 {
+			return 1
+		}
+	}
+	for _, ip := range ips {
+		kind := "A"
+		if strings.Contains(ip, ":") {
+			kind = "AAAA"
+		}
+		fmt.Fprintf(w, "    %-4s %s\n", kind, ip)
+	}
+	return 0
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const retryBaseDelay = 500 * time.Millisecond // 首次重试前的等待时间, 之后每次翻倍
+
+// --retry-on 中可用的错误类型名称
+var retryClassNames = map[string]string{
+	"timeout": errCodeTimeout,
+	"reset":   errCodeReset,
+	"closed":  errCodeClosed,
+	"refused": errCodeRefused,
+	"noroute": errCodeNoRoute,
+	"dns":     errCodeDNS,
+}
+
+const defaultRetryOn = "timeout,reset,closed" // 默认只重试偶发性的错误
+
+// 解析 --retry-on 参数, 返回可重试的错误分类代码集合
+func parseRetryOn(s string) (map[string]bool, error) {
+	codes := map[string]bool{}
+	for _, name := range strings.Split(s, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "all" {
+			for _, code := range retryClassNames {
+				codes[code] = true
+			}
+			codes[errCodeOther] = true
+			continue
+		}
+		code, ok := retryClassNames[name]
+		if !ok {
+			return nil, fmt.Errorf("无效的重试错误类型: %q (可选: timeout, reset, closed, refused, noroute, dns, all)", name)
+		}
+		codes[code] = true
+	}
+	return codes, nil
+}
+
+// 判断状态响应是否为空 (空内容、{} 或缺少任一必需字段), 服务器启动过程中可能返回这样的状态
+func isEmptyStatus(raw string, required []string) bool {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(raw), &fields); err != nil || len(fields) == 0 {
+		return true
+	}
+	for _, name := range required {
+		if v, ok := fields[name]; !ok || string(v) == "null" {
+			return true
+		}
+	}
+	return false
+}
+
+// 查询服务器状态, 遇到可重试的错误时按指数退避重试
+func queryWithRetry(host string, port uint16, opts queryOptions) (*ServerStatus, error) {
+	delay := retryBaseDelay
+	for attempt := 0; ; attempt++ {
+		status, err := queryStatus(host, port, opts)
+		// 等待后已超过 --deadline 时不再重试
+		if attempt >= opts.Retries || (!queryDeadline.IsZero() && time.Now().Add(delay).After(queryDeadline)) {
+			return status, err
+		}
+		if status != nil {
+			// 已收到服务器响应 (如 JSON 解析失败) 时重试没有意义, 除非指定了 --retry-on-empty 且响应为空
+			if opts.RetryEmpty == nil || !isEmptyStatus(status.Raw, opts.RetryEmpty) {
+				return status, err
+			}
+		} else if err == nil || !opts.RetryOn[classifyError(err)] {
+			return status, err
+		}
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
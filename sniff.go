@@ -0,0 +1,57 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+)
+
+// 目标端口上运行的不是 Minecraft 服务 (如误填了 HTTP 或 SSH 端口)
+type notMinecraftError struct {
+	service string // 根据响应内容推测的服务类型, 无法识别时为空
+}
+
+func (e *notMinecraftError) Error() string {
+	if e.service != "" {
+		return fmt.Sprintf("这看起来不是 Minecraft 服务器 (响应像是 %s), 请检查端口是否正确", e.service)
+	}
+	return "这看起来不是 Minecraft 服务器 (响应不符合协议格式), 请检查端口是否正确"
+}
+
+// 常见服务的响应开头
+var serviceSignatures = []struct {
+	prefix  string
+	service string
+}{
+	{"HTTP/", "HTTP 服务"},
+	{"SSH-", "SSH 服务"},
+	{"<", "HTML 网页"},
+	{"220", "FTP / SMTP 服务"},
+	{"+OK", "POP3 服务"},
+	{"* OK", "IMAP 服务"},
+	{"-ERR", "Redis 服务"},
+	{"\x15\x03", "TLS 加密服务"},
+	{"\x16\x03", "TLS 加密服务"},
+}
+
+// 在读取数据包前检查响应开头是否符合 Minecraft 协议
+// 数据包以 VarInt 长度开头, 长度小于 128 时只占 1 字节, 其后紧跟包 ID; 据此可在不等待完整数据包的情况下
+// 识别出 HTTP、SSH 等其他服务, 避免等到超时或得到难以理解的解析错误
+// 数据不足 2 字节 (如连接已关闭) 时不做判断, 由后续读取报告错误
+// strict 为 false 时 (允许先收到其他数据包) 仅报告能识别出的服务
+func sniffMinecraft(r *bufio.Reader, packetID byte, strict bool) error {
+	head, err := r.Peek(2)
+	if err != nil || head[0]&0x80 != 0 || head[1] == packetID {
+		return nil
+	}
+	head, _ = r.Peek(r.Buffered())
+	for _, sig := range serviceSignatures {
+		if bytes.HasPrefix(head, []byte(sig.prefix)) {
+			return &notMinecraftError{service: sig.service}
+		}
+	}
+	if !strict {
+		return nil
+	}
+	return &notMinecraftError{}
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+const spinnerFrames = `-\|/`
+
+// 在当前行显示旋转指示器, 直到调用返回的 stop 函数 (stop 会擦除指示器所在行)
+func startSpinner(msg string) (stop func()) {
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(100 * time.Millisecond)
+		defer ticker.Stop()
+		for i := 0; ; i++ {
+			fmt.Printf("\r%c %s", spinnerFrames[i%len(spinnerFrames)], msg)
+			select {
+			case <-done:
+				fmt.Print("\r\033[K")
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		wg.Wait()
+	}
+}
+
+// 执行 fn, 执行期间按需显示旋转指示器
+func withSpinner(show bool, msg string, fn func()) {
+	if !show {
+		fn()
+		return
+	}
+	stop := startSpinner(msg)
+	fn()
+	stop()
+}
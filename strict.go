@@ -0,0 +1,232 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+// 严格模式 (--strict): 按规范校验状态 JSON 的结构, 而不是尽量解析
+var strictParse bool
+
+// 状态 JSON 中除 version、players、description、favicon 外允许出现的字段 (原版与常见模组加载器的扩展)
+var knownStatusFields = map[string]string{
+	"enforcesSecureChat":  "boolean",
+	"previewsChat":        "boolean",
+	"preventsChatReports": "boolean",
+	"modinfo":             "object",
+	"forgeData":           "object",
+	"isModded":            "boolean",
+}
+
+const maxComponentDepth = 512 // 组件嵌套层数上限
+
+// 聊天组件中的格式字段
+var componentBoolFields = []string{"bold", "italic", "underlined", "strikethrough", "obfuscated"}
+
+// 校验状态 JSON 是否符合规范, 返回第一个不符合之处
+func validateStatus(raw string) error {
+	dec := json.NewDecoder(strings.NewReader(raw))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return fmt.Errorf("JSON 之后存在多余内容")
+	}
+	root, ok := v.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("根元素应为对象")
+	}
+
+	version, err := requireObject(root, "version", "version")
+	if err != nil {
+		return err
+	}
+	if err := requireType(version, "name", "version.name", "string"); err != nil {
+		return err
+	}
+	if err := requireType(version, "protocol", "version.protocol", "integer"); err != nil {
+		return err
+	}
+
+	players, err := requireObject(root, "players", "players")
+	if err != nil {
+		return err
+	}
+	for _, field := range []string{"online", "max"} {
+		if err := requireType(players, field, "players."+field, "integer"); err != nil {
+			return err
+		}
+	}
+	if sample, ok := players["sample"]; ok {
+		list, ok := sample.([]interface{})
+		if !ok {
+			return fmt.Errorf("字段 players.sample 应为数组")
+		}
+		for i, entry := range list {
+			path := fmt.Sprintf("players.sample[%d]", i)
+			obj, ok := entry.(map[string]interface{})
+			if !ok {
+				return fmt.Errorf("字段 %s 应为对象", path)
+			}
+			for _, field := range []string{"name", "id"} {
+				if err := requireType(obj, field, path+"."+field, "string"); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	desc, ok := root["description"]
+	if !ok {
+		return fmt.Errorf("缺少必需字段 description")
+	}
+	if err := validateComponent(desc, "description", 0); err != nil {
+		return err
+	}
+
+	if favicon, ok := root["favicon"]; ok {
+		s, ok := favicon.(string)
+		if !ok || !strings.HasPrefix(s, "data:image/png;base64,") {
+			return fmt.Errorf("字段 favicon 应为 data:image/png;base64, 开头的字符串")
+		}
+	}
+
+	for key, value := range root {
+		switch key {
+		case "version", "players", "description", "favicon":
+			continue
+		}
+		want, ok := knownStatusFields[key]
+		if !ok {
+			return fmt.Errorf("未知字段 %s", key)
+		}
+		if !hasType(value, want) {
+			return fmt.Errorf("字段 %s 应为%s", key, typeNames[want])
+		}
+	}
+	return nil
+}
+
+// 显示状态 JSON 中的非标准字段 (--extra-fields)
+var showExtraFields bool
+
+// 状态 JSON 中不属于标准结构的顶层字段 (如插件添加的 uptime、tps), 值为原始 JSON, 无法解析时返回 nil
+func extraStatusFields(raw string) map[string]json.RawMessage {
+	var root map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(raw), &root); err != nil {
+		return nil
+	}
+	for key := range root {
+		if _, known := knownStatusFields[key]; known || slices.Contains(statusFieldNames, key) {
+			delete(root, key)
+		}
+	}
+	return root
+}
+
+// 校验聊天组件 (字符串、对象或数组)
+func validateComponent(v interface{}, path string, depth int) error {
+	if depth > maxComponentDepth {
+		return fmt.Errorf("字段 %s 嵌套层数过深", path)
+	}
+	switch c := v.(type) {
+	case string:
+		return nil
+	case []interface{}:
+		for i, child := range c {
+			if err := validateComponent(child, fmt.Sprintf("%s[%d]", path, i), depth+1); err != nil {
+				return err
+			}
+		}
+		return nil
+	case map[string]interface{}:
+		if text, ok := c["text"]; ok && !hasType(text, "string") {
+			return fmt.Errorf("字段 %s.text 应为字符串", path)
+		}
+		if color, ok := c["color"]; ok {
+			name, ok := color.(string)
+			// reset 表示恢复默认颜色, 不对应具体的 ANSI 颜色
+			if !ok || (name != "reset" && getColorANSI(name) == "") {
+				return fmt.Errorf("字段 %s.color 应为颜色名称或 #RRGGBB", path)
+			}
+		}
+		for _, field := range componentBoolFields {
+			if value, ok := c[field]; ok && !hasType(value, "boolean") {
+				return fmt.Errorf("字段 %s.%s 应为布尔值", path, field)
+			}
+		}
+		if extra, ok := c["extra"]; ok {
+			list, ok := extra.([]interface{})
+			if !ok {
+				return fmt.Errorf("字段 %s.extra 应为数组", path)
+			}
+			for i, child := range list {
+				if err := validateComponent(child, fmt.Sprintf("%s.extra[%d]", path, i), depth+1); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+	return fmt.Errorf("字段 %s 应为字符串、对象或数组", path)
+}
+
+var typeNames = map[string]string{
+	"string":  "字符串",
+	"integer": "整数",
+	"boolean": "布尔值",
+	"object":  "对象",
+}
+
+// 判断值是否为指定类型
+func hasType(v interface{}, want string) bool {
+	switch want {
+	case "string":
+		_, ok := v.(string)
+		return ok
+	case "integer":
+		n, ok := v.(json.Number)
+		if !ok {
+			return false
+		}
+		_, err := n.Int64()
+		return err == nil
+	case "boolean":
+		_, ok := v.(bool)
+		return ok
+	case "object":
+		_, ok := v.(map[string]interface{})
+		return ok
+	}
+	return false
+}
+
+// 读取必需的对象字段
+func requireObject(obj map[string]interface{}, key, path string) (map[string]interface{}, error) {
+	v, ok := obj[key]
+	if !ok {
+		return nil, fmt.Errorf("缺少必需字段 %s", path)
+	}
+	child, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("字段 %s 应为对象", path)
+	}
+	return child, nil
+}
+
+// 检查必需字段的类型
+func requireType(obj map[string]interface{}, key, path, want string) error {
+	v, ok := obj[key]
+	if !ok {
+		return fmt.Errorf("缺少必需字段 %s", path)
+	}
+	if !hasType(v, want) {
+		return fmt.Errorf("字段 %s 应为%s", path, typeNames[want])
+	}
+	return nil
+}
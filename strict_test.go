@@ -0,0 +1,26 @@
+package main
+
+import "testing"
+
+func TestValidateStatusColors(t *testing.T) {
+	tests := []struct {
+		name    string
+		color   string
+		wantErr bool
+	}{
+		{"颜色名称", `"gold"`, false},
+		{"十六进制颜色", `"#55ffaa"`, false},
+		{"reset", `"reset"`, false},
+		{"未知颜色名称", `"rainbow"`, true},
+		{"非字符串", `1`, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			raw := `{"version":{"name":"Paper","protocol":765},"players":{"max":20,"online":0},` +
+				`"description":{"text":"","extra":[{"text":"A","color":` + tt.color + `}]}}`
+			if err := validateStatus(raw); (err != nil) != tt.wantErr {
+				t.Errorf("validateStatus() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// 表格各列的最大显示宽度 (MOTD 列使用剩余的终端宽度)
+const (
+	tableAddrWidth    = 32
+	tableVersionWidth = 24
+)
+
+// 文本的显示宽度 (忽略 ANSI 转义序列)
+func visibleWidth(s string) int {
+	width, inEscape := 0, false
+	for _, r := range s {
+		switch {
+		case inEscape:
+			inEscape = r != 'm'
+		case r == '\033':
+			inEscape = true
+		default:
+			width += runeWidth(r)
+		}
+	}
+	return width
+}
+
+// 将带 ANSI 样式的文本截断到指定显示宽度, 超出部分以 … 代替, 截断后补充重置代码
+func truncateANSI(s string, width int) string {
+	if visibleWidth(s) <= width {
+		return s
+	}
+	var b strings.Builder
+	used, inEscape := 0, false
+	for _, r := range s {
+		switch {
+		case inEscape:
+			inEscape = r != 'm'
+		case r == '\033':
+			inEscape = true
+		default:
+			if used+runeWidth(r) > width-1 {
+				b.WriteString("…")
+				if useColor {
+					b.WriteString(ansiReset)
+				}
+				return b.String()
+			}
+			used += runeWidth(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// 按显示宽度在右侧补齐空格
+func padRight(s string, width int) string {
+	if n := width - visibleWidth(s); n > 0 {
+		return s + strings.Repeat(" ", n)
+	}
+	return s
+}
+
+// 按显示宽度在左侧补齐空格
+func padLeft(s string, width int) string {
+	if n := width - visibleWidth(s); n > 0 {
+		return strings.Repeat(" ", n) + s
+	}
+	return s
+}
+
+// 将聊天组件中的换行替换为空格, 用于单行显示
+func flattenComponent(c ChatComponent) ChatComponent {
+	c.Text = strings.ReplaceAll(c.Text, "\n", " ")
+	extra := make([]ChatComponentMixed, len(c.Extra))
+	for i, child := range c.Extra {
+		if child.TextComponent != nil {
+			flat := flattenComponent(*child.TextComponent)
+			child.TextComponent = &flat
+		} else {
+			child.RawString = strings.ReplaceAll(child.RawString, "\n", " ")
+		}
+		extra[i] = child
+	}
+	c.Extra = extra
+	return c
+}
+
+// 表格中单行显示的 MOTD (保留颜色)
+func tableMOTD(desc interface{}) string {
+	component, err := toChatComponent(desc)
+	if err != nil {
+		return ""
+	}
+	component = flattenComponent(component)
+	if !useColor {
+		return strings.Join(strings.Fields(plainText(component)), " ")
+	}
+	return renderANSI(component, cliRenderOptions())
+}
+
+// 以对齐的表格输出批量查询结果: 地址 版本 在线人数 延迟 MOTD
+func printTable(w io.Writer, results []queryResult) {
+	header := []string{"地址", "版本", "在线人数", "延迟", "MOTD"}
+	rows := make([][]string, 0, len(results))
+	for _, r := range results {
+		addr := r.Target.Address
+		if r.Host != "" {
+			addr = fmt.Sprintf("%s:%d", r.Host, r.Port)
+		}
+		addr = truncateWidth(addr, tableAddrWidth)
+		if r.Err != nil {
+			reason := strings.ReplaceAll(r.Err.Error(), "\n", " ")
+			rows = append(rows, []string{addr, colorize("错误", "red"), "-", "-", colorize(reason, "red")})
+			continue
+		}
+		s := r.Status
+		players := colorize("N/A", "gray")
+		if s.Players != nil {
+			players = colorizePlayers(s.Players.Online, s.Players.Max)
+		}
+		version := colorize("未知版本", "gray")
+		if s.Version != nil {
+			version = truncateANSI(renderLegacy(s.Version.Name), tableVersionWidth)
+		}
+		rows = append(rows, []string{addr, version, players, colorizePing(s.Ping), tableMOTD(s.Description)})
+	}
+
+	// 计算前四列的宽度, MOTD 列使用剩余的终端宽度
+	widths := make([]int, len(header)-1)
+	for i := range widths {
+		widths[i] = visibleWidth(header[i])
+		for _, row := range rows {
+			widths[i] = max(widths[i], visibleWidth(row[i]))
+		}
+	}
+	used := 0
+	for _, width := range widths {
+		used += width + 2
+	}
+	room := max(termWidth()-used, 10)
+
+	line := func(row []string) string {
+		cells := make([]string, 0, len(row))
+		for i, width := range widths {
+			if i == 2 || i == 3 {
+				cells = append(cells, padLeft(row[i], width)) // 数字列右对齐
+			} else {
+				cells = append(cells, padRight(row[i], width))
+			}
+		}
+		cells = append(cells, truncateANSI(row[len(row)-1], room))
+		return strings.Join(cells, "  ")
+	}
+	fmt.Fprintln(w, line(header))
+	for _, row := range rows {
+		fmt.Fprintln(w, line(row))
+	}
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// 主题中覆盖的颜色 (--theme): 颜色名称 -> #RRGGBB 或 ANSI SGR 参数 (如 "38;5;208")
+var themeColors = map[string]string{}
+
+// 加载颜色主题文件
+// 文件为 JSON 对象, 键为颜色名称 (如 gold) 或传统颜色码 (如 6), 值为 #RRGGBB 或 SGR 参数, 未指定的颜色使用内置配色
+func loadTheme(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var theme map[string]string
+	if err := json.Unmarshal(data, &theme); err != nil {
+		return fmt.Errorf("主题文件解析失败: %w", err)
+	}
+
+	for key, value := range theme {
+		name := strings.ToLower(strings.TrimPrefix(key, "§"))
+		if r := []rune(name); len(r) == 1 {
+			if n, ok := legacyColorNames[r[0]]; ok {
+				name = n
+			}
+		}
+		if _, ok := minecraftColorMap[name]; !ok {
+			return fmt.Errorf("主题中的颜色名称无效: %s", key)
+		}
+		if _, ok := parseHexColor(value); !ok && !isSGRParams(value) {
+			return fmt.Errorf("主题中 %s 的颜色值无效: %s (应为 #RRGGBB 或 SGR 参数)", key, value)
+		}
+		themeColors[name] = value
+	}
+	return nil
+}
+
+// 判断是否为 SGR 参数 (仅包含数字与分号)
+func isSGRParams(s string) bool {
+	return s != "" && strings.Trim(s, "0123456789;") == ""
+}
+
+// 主题中覆盖的颜色对应的 ANSI 码
+func themeANSI(name string, depth int) (string, bool) {
+	value, ok := themeColors[name]
+	if !ok || depth == colorDepthNone {
+		return "", ok
+	}
+	if _, isHex := parseHexColor(value); isHex {
+		return colorANSI(value, depth), true
+	}
+	return "\033[" + value + "m", true
+}
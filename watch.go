@@ -0,0 +1,31 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"time"
+)
+
+const defaultWatchInterval = 10 * time.Second // 监视模式默认查询间隔
+
+// 监视模式: 按固定间隔重复执行查询, 直到被中断
+// 首次查询立即开始, 之后对齐到间隔的整数倍时刻 (如每 10s 的 :00、:10), 避免查询耗时导致时间漂移;
+// 查询耗时超过间隔时跳过已错过的时刻
+// stamp 为 true 时在每次结果前打印时间戳 (JSON 输出时由结果中的 time 字段提供)
+func runWatch(w io.Writer, interval time.Duration, timeFormat string, stamp bool, run func() int) {
+	if interval <= 0 {
+		interval = defaultWatchInterval
+	}
+	for {
+		if stamp {
+			fmt.Fprintf(w, "\n[%s]\n", time.Now().Format(timeFormat))
+		}
+		run()
+		time.Sleep(time.Until(nextTick(time.Now(), interval)))
+	}
+}
+
+// 下一个对齐到 interval 整数倍的时刻
+func nextTick(now time.Time, interval time.Duration) time.Time {
+	return now.Truncate(interval).Add(interval)
+}